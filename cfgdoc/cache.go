@@ -0,0 +1,93 @@
+package cfgdoc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// cacheVersion is part of every cache key, and is increased whenever the parsed Struct and Field types or the way
+// they are parsed change, so that entries written by an older version of the tool are never used
+const cacheVersion = 4
+
+// cacheEntry is what ParseOptions.CacheDir holds for each parsed file: the results of docFileStructs, and the
+// warnings written while parsing the file, which are written again when the entry is used
+type cacheEntry struct {
+	// Path, ModTime, and Size are the file's at the time it was parsed, checked again when the entry is read in case
+	// of a hash collision
+	Path    string
+	ModTime int64
+	Size    int64
+
+	Structs  map[string]Struct
+	Types    map[string]string
+	Warnings string
+}
+
+// parseCache reads and writes the cache entries of parsed files in a directory. Entries are keyed by the path, the
+// modification time, and the size of the file, along with the parse options that change the result, so a file is
+// parsed again as soon as it changes. Entries are written to a temporary file that is renamed into place, so that
+// the directory can be shared by runs at the same time
+type parseCache struct {
+	dir               string
+	includeUnexported bool
+}
+
+// entryPath returns the path of the cache entry of a file with the given stat info
+func (c *parseCache) entryPath(path string, info os.FileInfo) string {
+	key := fmt.Sprintf("%d\x00%s\x00%d\x00%d\x00%t", cacheVersion, path, info.ModTime().UnixNano(), info.Size(),
+		c.includeUnexported)
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// get returns the cache entry of the file at path, or false if there isn't one for the file as it is now or it
+// can't be read, in which case the file should be parsed
+func (c *parseCache) get(path string) (*cacheEntry, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	data, err := os.ReadFile(c.entryPath(path, info))
+	if err != nil {
+		return nil, false
+	}
+	var entry cacheEntry
+	if err = json.Unmarshal(data, &entry); err != nil || entry.Path != path ||
+		entry.ModTime != info.ModTime().UnixNano() || entry.Size != info.Size() {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// put writes the cache entry of the file at path, with the stat info it had before it was parsed so that a change
+// made while parsing it invalidates the entry. Errors are ignored, since the file is just parsed again next time
+func (c *parseCache) put(path string, info os.FileInfo, entry *cacheEntry) {
+	entry.Path = path
+	entry.ModTime = info.ModTime().UnixNano()
+	entry.Size = info.Size()
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(c.dir, 0755); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(c.dir, "entry-*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.entryPath(path, info))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
@@ -0,0 +1,154 @@
+package cfgdoc
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// benchmarkTree is the fixture gochan tree, parsed for the structs marked with ConfigDirective as with -recursive
+const benchmarkTree = "../testdata/gochan"
+
+func BenchmarkParse(b *testing.B) {
+	b.Run("uncached", func(b *testing.B) {
+		parseOpts := ParseOptions{MarkedOnly: true, Warnings: io.Discard}
+		for i := 0; i < b.N; i++ {
+			if _, err := ParseWith(benchmarkTree, parseOpts); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		parseOpts := ParseOptions{MarkedOnly: true, Warnings: io.Discard, CacheDir: b.TempDir()}
+		// the first run fills the cache, so that every file is read from it in the timed runs
+		if _, err := ParseWith(benchmarkTree, parseOpts); err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := ParseWith(benchmarkTree, parseOpts); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// cacheTestFile is a config file whose default the tests replace to change it
+const cacheTestFile = `package config
+
+// Config is the config
+type Config struct {
+	// SiteName is the name of the site
+	// Default: Gochan
+	SiteName string
+}
+`
+
+// writeCacheTestFile writes cacheTestFile to path with the given default instead of Gochan
+func writeCacheTestFile(t *testing.T, path string, def string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(strings.Replace(cacheTestFile, "Gochan", def, 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// parseDefault parses dir and returns the default of Config.SiteName
+func parseDefault(t *testing.T, dir string, parseOpts ParseOptions) string {
+	t.Helper()
+	structs, err := ParseWith(dir, parseOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(structs) != 1 || len(structs[0].Fields) != 1 {
+		t.Fatalf("expected Config with one field, got %+v", structs)
+	}
+	return structs[0].Fields[0].Default
+}
+
+func TestParseCacheInvalidation(t *testing.T) {
+	tests := []struct {
+		name string
+		def  string // the new default, written over the cached file
+		keep string // which of the file's stat info is restored after writing it, so that only the other changes
+	}{
+		// a default of the same length keeps the size, so only the modification time tells the file changed
+		{name: "mtime", def: "Gochon", keep: "size"},
+		{name: "size", def: "Gochan Imageboard", keep: "mtime"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "config.go")
+			writeCacheTestFile(t, path, "Gochan")
+			parseOpts := ParseOptions{Warnings: io.Discard, CacheDir: t.TempDir()}
+			if def := parseDefault(t, dir, parseOpts); def != "Gochan" {
+				t.Fatalf("expected the default Gochan, got %q", def)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			writeCacheTestFile(t, path, test.def)
+			modTime := info.ModTime().Add(time.Second)
+			if test.keep == "mtime" {
+				modTime = info.ModTime()
+			}
+			if err = os.Chtimes(path, modTime, modTime); err != nil {
+				t.Fatal(err)
+			}
+			if def := parseDefault(t, dir, parseOpts); def != test.def {
+				t.Errorf("expected the file to be parsed again with the default %q, got the cached %q", test.def, def)
+			}
+		})
+	}
+}
+
+func TestParseCacheCorruptEntry(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(entry []byte) []byte
+	}{
+		{name: "truncated", corrupt: func(entry []byte) []byte { return entry[:len(entry)/2] }},
+		{name: "empty", corrupt: func([]byte) []byte { return nil }},
+		{name: "garbage", corrupt: func([]byte) []byte { return []byte("not a cache entry") }},
+		// valid JSON, but not the entry of the file
+		{name: "other file", corrupt: func([]byte) []byte { return []byte(`{"Path": "other.go"}`) }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeCacheTestFile(t, filepath.Join(dir, "config.go"), "Gochan")
+			uncached, err := ParseWith(dir, ParseOptions{Warnings: io.Discard})
+			if err != nil {
+				t.Fatal(err)
+			}
+			parseOpts := ParseOptions{Warnings: io.Discard, CacheDir: t.TempDir()}
+			if _, err = ParseWith(dir, parseOpts); err != nil {
+				t.Fatal(err)
+			}
+			entries, err := filepath.Glob(filepath.Join(parseOpts.CacheDir, "*.json"))
+			if err != nil || len(entries) != 1 {
+				t.Fatalf("expected one cache entry, got %q (%v)", entries, err)
+			}
+			data, err := os.ReadFile(entries[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = os.WriteFile(entries[0], test.corrupt(data), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cached, err := ParseWith(dir, parseOpts)
+			if err != nil {
+				t.Fatalf("expected the file to be parsed again, got %s", err)
+			}
+			if !reflect.DeepEqual(cached, uncached) {
+				t.Errorf("expected the same structs as without the cache:\n%+v\ngot:\n%+v", uncached, cached)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package cfgdoc
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// columnAlign is the alignment of a Markdown table column, given by the colons in its divider
+type columnAlign int
+
+const (
+	alignLeft columnAlign = iota
+	alignCenter
+	alignRight
+)
+
+// fieldColumn describes one of the columns of a table of fields before the Info column, which is always the last
+// one. The length of the column is that of its longest cell in the non-deprecated fields, or minLength if that is
+// longer, and its width in a Markdown table is the length plus padding
+type fieldColumn struct {
+	header    string
+	minLength int
+	padding   int
+	align     columnAlign // the alignment of the column if opts.AlignColumns is set
+
+	// present returns true if the table gets the column. If it is nil, the table gets the column if any of its
+	// cells aren't empty
+	present func(t *fieldTable) bool
+
+	// text returns the cell of a field as plain text, as written in terminal output
+	text func(opts *Options, str *Struct, field *Field) string
+
+	// markdown returns the cell of a field in a Markdown table. If it is nil, the cell is text escaped with
+	// markdownCellText
+	markdown func(opts *Options, str *Struct, field *Field) string
+
+	// htmlClass returns the class of a field's cell in an HTML table, or is nil if its cells have none
+	htmlClass func(opts *Options, str *Struct, field *Field) string
+}
+
+// markdownCell returns the cell of a field in a Markdown table
+func (c *fieldColumn) markdownCell(opts *Options, str *Struct, field *Field) string {
+	if c.markdown != nil {
+		return c.markdown(opts, str, field)
+	}
+	return markdownCellText(c.text(opts, str, field))
+}
+
+// fieldColumns are the columns that a table of fields can have, in the order they are written in
+var fieldColumns = []fieldColumn{
+	{
+		header:    "Field",
+		minLength: 6,
+		padding:   1,
+		present:   func(*fieldTable) bool { return true },
+		text:      func(_ *Options, _ *Struct, field *Field) string { return field.Name },
+		markdown:  func(opts *Options, str *Struct, field *Field) string { return markdownFieldCell(str, field, opts) },
+	},
+	{
+		header:    "Struct",
+		minLength: 6,
+		padding:   1,
+		present:   func(t *fieldTable) bool { return t.flat },
+		text:      func(_ *Options, str *Struct, _ *Field) string { return str.Name },
+	},
+	{
+		header:    "Type",
+		minLength: 5,
+		padding:   1,
+		present:   func(*fieldTable) bool { return true },
+		text:      func(opts *Options, _ *Struct, field *Field) string { return field.TypeText(opts.ResolveAliases) },
+	},
+	{
+		header:    "Board option",
+		minLength: 12,
+		padding:   1,
+		align:     alignCenter,
+		present:   func(t *fieldTable) bool { return boardOptionColumn(t.named, t.opts, t.strs...) },
+		text: func(opts *Options, str *Struct, field *Field) string {
+			return yesNo(str.IsBoardOption(field, opts.BoardStructs))
+		},
+		htmlClass: func(opts *Options, str *Struct, field *Field) string {
+			return "board-option-" + strings.ToLower(yesNo(str.IsBoardOption(field, opts.BoardStructs)))
+		},
+	},
+	{
+		header:    "Required",
+		minLength: 8,
+		padding:   1,
+		align:     alignCenter,
+		present:   func(t *fieldTable) bool { return anyField(func(f *Field) bool { return f.Required }, t.strs...) },
+		text:      func(_ *Options, _ *Struct, field *Field) string { return yesNo(field.Required) },
+	},
+	{
+		header:    "Default",
+		minLength: 8,
+		padding:   3,
+		text:      func(opts *Options, _ *Struct, field *Field) string { return defaultCell(field, opts) },
+	},
+	{
+		header:    "Since",
+		minLength: 5,
+		padding:   1,
+		text:      func(_ *Options, _ *Struct, field *Field) string { return field.Since },
+	},
+	{
+		header:    "Platform",
+		minLength: 8,
+		padding:   1,
+		text:      func(_ *Options, _ *Struct, field *Field) string { return field.PlatformText() },
+	},
+}
+
+// fieldTable is the columns of fieldColumns that a table of the fields of some structs gets, with the lengths that
+// fit their Markdown cells
+type fieldTable struct {
+	opts  *Options
+	strs  []Struct
+	named bool // passed to boardOptionColumn
+	flat  bool // whether the fields of the structs are sorted together, which adds the Struct column
+
+	columns []fieldColumn
+	lengths []int
+}
+
+// newFieldTable returns the table of the non-deprecated fields of the given structs
+func newFieldTable(opts *Options, named bool, flat bool, strs ...Struct) *fieldTable {
+	t := &fieldTable{opts: opts, strs: strs, named: named, flat: flat}
+	for c := range fieldColumns {
+		column := &fieldColumns[c]
+		length := 0
+		for s := range strs {
+			for f := range strs[s].Fields {
+				if field := &strs[s].Fields[f]; !field.IsDeprecated() {
+					length = max(length, utf8.RuneCountInString(column.markdownCell(opts, &strs[s], field)))
+				}
+			}
+		}
+		if column.present == nil && length == 0 || column.present != nil && !column.present(t) {
+			continue
+		}
+		t.columns = append(t.columns, *column)
+		t.lengths = append(t.lengths, max(length, column.minLength))
+	}
+	return t
+}
+
+// column returns the index of the column with the given header, or -1 if the table doesn't have it
+func (t *fieldTable) column(header string) int {
+	for c := range t.columns {
+		if t.columns[c].header == header {
+			return c
+		}
+	}
+	return -1
+}
+
+// widths returns the padded width of each column of the table, followed by the Info column, which is left
+// unpadded in data rows and gets a fixed-width divider
+func (t *fieldTable) widths() []int {
+	widths := make([]int, 0, len(t.columns)+1)
+	for c := range t.columns {
+		widths = append(widths, t.lengths[c]+t.columns[c].padding)
+	}
+	return append(widths, 14)
+}
+
+// alignments returns the alignment of each column returned by widths, or nil if opts.AlignColumns isn't set
+func (t *fieldTable) alignments() []columnAlign {
+	if !t.opts.AlignColumns {
+		return nil
+	}
+	aligns := make([]columnAlign, 0, len(t.columns)+1)
+	for c := range t.columns {
+		aligns = append(aligns, t.columns[c].align)
+	}
+	return append(aligns, alignLeft)
+}
+
+// headers returns the header of each column returned by widths
+func (t *fieldTable) headers() []string {
+	headers := make([]string, 0, len(t.columns)+1)
+	for c := range t.columns {
+		headers = append(headers, t.columns[c].header)
+	}
+	return append(headers, "Info")
+}
+
+// markdownCells returns the Markdown cells of a field in each column but the Info column
+func (t *fieldTable) markdownCells(str *Struct, field *Field) []string {
+	cells := make([]string, 0, len(t.columns)+1)
+	for c := range t.columns {
+		cells = append(cells, t.columns[c].markdownCell(t.opts, str, field))
+	}
+	return cells
+}
+
+// textCells returns the plain text cells of a field in each column but the Info column
+func (t *fieldTable) textCells(str *Struct, field *Field) []string {
+	cells := make([]string, 0, len(t.columns)+1)
+	for c := range t.columns {
+		cells = append(cells, t.columns[c].text(t.opts, str, field))
+	}
+	return cells
+}
+
+// htmlCells returns the <td> elements of a field in each column but the Info column, with their text escaped
+func (t *fieldTable) htmlCells(str *Struct, field *Field) string {
+	var cells strings.Builder
+	for c := range t.columns {
+		cells.WriteString("<td")
+		if t.columns[c].htmlClass != nil {
+			cells.WriteString(" class=\"" + t.columns[c].htmlClass(t.opts, str, field) + "\"")
+		}
+		cells.WriteString(">" + html.EscapeString(t.columns[c].text(t.opts, str, field)) + "</td>")
+	}
+	return cells.String()
+}
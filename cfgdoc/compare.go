@@ -0,0 +1,115 @@
+package cfgdoc
+
+import (
+	"sort"
+	"strings"
+)
+
+// Compare returns a Markdown report of the differences between two parsed versions of the same structs, with a
+// section for each struct that was added, removed, or has fields that were added, removed, or had their type,
+// default, or deprecation changed. Embedded structs are compared as structs of their own rather than as fields
+func Compare(oldStructs, newStructs []Struct) string {
+	oldMap := structsByName(oldStructs)
+	newMap := structsByName(newStructs)
+	var names []string
+	for name := range oldMap {
+		names = append(names, name)
+	}
+	for name := range newMap {
+		if _, ok := oldMap[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var builder strings.Builder
+	for _, name := range names {
+		oldStr, inOld := oldMap[name]
+		newStr, inNew := newMap[name]
+		var changes []string
+		switch {
+		case !inOld:
+			changes = append(changes, "Added struct")
+		case !inNew:
+			changes = append(changes, "Removed struct")
+		default:
+			changes = fieldChanges(&oldStr, &newStr)
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		if builder.Len() > 0 {
+			builder.WriteString("\n")
+		}
+		builder.WriteString("## " + name + "\n")
+		for _, change := range changes {
+			builder.WriteString("- " + change + "\n")
+		}
+	}
+	if builder.Len() == 0 {
+		return "No configuration changes.\n"
+	}
+	return builder.String()
+}
+
+// fieldChanges returns a description of each field that was added to, removed from, or changed between two
+// versions of a struct, in the order the fields are declared in (removed fields last)
+func fieldChanges(oldStr, newStr *Struct) []string {
+	oldFields := make(map[string]*Field, len(oldStr.Fields))
+	for f, field := range oldStr.Fields {
+		if field.Name != "" {
+			oldFields[field.Name] = &oldStr.Fields[f]
+		}
+	}
+	var changes []string
+	newFields := make(map[string]bool, len(newStr.Fields))
+	for f, field := range newStr.Fields {
+		if field.Name == "" {
+			continue
+		}
+		newFields[field.Name] = true
+		oldField, ok := oldFields[field.Name]
+		if !ok {
+			change := "Added `" + field.Name + "` (`" + field.Type + "`"
+			if field.Default != "" {
+				change += ", default: " + field.Default
+			}
+			changes = append(changes, change+")")
+			continue
+		}
+		changes = append(changes, modifiedField(oldField, &newStr.Fields[f])...)
+	}
+	for _, field := range oldStr.Fields {
+		if field.Name != "" && !newFields[field.Name] {
+			changes = append(changes, "Removed `"+field.Name+"`")
+		}
+	}
+	return changes
+}
+
+// modifiedField returns a description of each change between two versions of the same field
+func modifiedField(oldField, newField *Field) []string {
+	var changes []string
+	if oldField.Type != newField.Type {
+		changes = append(changes, "`"+newField.Name+"` type changed from `"+oldField.Type+"` to `"+newField.Type+"`")
+	}
+	if oldField.Default != newField.Default {
+		changes = append(changes, "`"+newField.Name+"` default changed from "+defaultText(oldField.Default)+
+			" to "+defaultText(newField.Default))
+	}
+	switch wasDeprecated := oldField.IsDeprecated(); {
+	case !wasDeprecated && newField.IsDeprecated():
+		changes = append(changes, "`"+newField.Name+"` is now deprecated")
+	case wasDeprecated && !newField.IsDeprecated():
+		changes = append(changes, "`"+newField.Name+"` is no longer deprecated")
+	}
+	return changes
+}
+
+// defaultText returns def for a change description, or "(none)" if the field had no default
+func defaultText(def string) string {
+	if def == "" {
+		return "(none)"
+	}
+	return def
+}
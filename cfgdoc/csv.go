@@ -0,0 +1,47 @@
+package cfgdoc
+
+import (
+	"encoding/csv"
+	"slices"
+	"strings"
+)
+
+// RenderCSV renders one row per field of opts.CompositeStructs and opts.NamedStructs, using comma as the field
+// delimiter (e.g. ',' for CSV or '\t' for TSV). Unlike the other renderers, deprecated fields are included and
+// flagged in the Deprecated column
+func RenderCSV(structs []Struct, opts Options, comma rune) string {
+	structMap := tableStructs(structs, &opts)
+	var builder strings.Builder
+	w := csv.NewWriter(&builder)
+	w.Comma = comma
+	w.Write([]string{"Struct", "Field", "Type", "BoardOption", "Required", "Default", "Since", "Info", "Deprecated"})
+
+	for _, structName := range slices.Concat(opts.CompositeStructs, namedStructNames(structMap, &opts)) {
+		str, ok := structMap[structName]
+		if !ok {
+			continue
+		}
+		for _, field := range str.Fields {
+			w.Write([]string{
+				str.Name,
+				field.Name,
+				field.TypeText(opts.ResolveAliases),
+				yesNo(str.IsBoardOption(&field, opts.BoardStructs)),
+				yesNo(field.Required),
+				field.DefaultText(),
+				field.Since,
+				infoText(&field, &opts),
+				yesNo(field.IsDeprecated()),
+			})
+		}
+	}
+	w.Flush()
+	return builder.String()
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
@@ -0,0 +1,199 @@
+package cfgdoc
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// ParseDefaults parses the non-test Go files in dir for the function or package-level variable with the given name
+// (e.g. a func that returns a default config, or a default struct literal), and returns the values assigned to
+// struct fields by the composite literals in it, keyed by struct name and then field name. Only literal values
+// (strings, numbers, and true or false) are returned, with strings unquoted
+func ParseDefaults(dir string, name string) (map[string]map[string]string, error) {
+	paths, err := goFiles(dir, nil)
+	if err != nil {
+		return nil, err
+	}
+	defaults := make(map[string]map[string]string)
+	found := false
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		file, err := parseFile(fset, path, path)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range file.Decls {
+			switch dt := decl.(type) {
+			case *ast.FuncDecl:
+				if dt.Recv == nil && dt.Name.Name == name && dt.Body != nil {
+					found = true
+					literalDefaults(dt.Body, defaults)
+				}
+			case *ast.GenDecl:
+				if dt.Tok != token.VAR {
+					continue
+				}
+				for _, spec := range dt.Specs {
+					valueSpec := spec.(*ast.ValueSpec)
+					for n, ident := range valueSpec.Names {
+						if ident.Name == name && n < len(valueSpec.Values) {
+							found = true
+							literalDefaults(valueSpec.Values[n], defaults)
+						}
+					}
+				}
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no function or variable named %s found in %s", name, dir)
+	}
+	return defaults, nil
+}
+
+// literalDefaults adds the literal values assigned to fields by the composite literals of named types in node to
+// defaults. Literals with elided or qualified types are skipped, since their struct can't be determined by name
+func literalDefaults(node ast.Node, defaults map[string]map[string]string) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		typeIdent, ok := lit.Type.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			key, ok := kv.Key.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			val, ok := literalValue(kv.Value)
+			if !ok {
+				continue
+			}
+			if defaults[typeIdent.Name] == nil {
+				defaults[typeIdent.Name] = make(map[string]string)
+			}
+			defaults[typeIdent.Name][key.Name] = val
+		}
+		return true
+	})
+}
+
+// literalValue returns the text of expr as it would be written in a Default annotation if it is a basic literal,
+// a negated number, or true or false
+func literalValue(expr ast.Expr) (string, bool) {
+	switch et := expr.(type) {
+	case *ast.BasicLit:
+		if et.Kind == token.STRING {
+			val, err := strconv.Unquote(et.Value)
+			return val, err == nil
+		}
+		return et.Value, et.Kind == token.INT || et.Kind == token.FLOAT
+	case *ast.Ident:
+		return et.Name, et.Name == "true" || et.Name == "false"
+	case *ast.UnaryExpr:
+		if lit, ok := et.X.(*ast.BasicLit); ok && et.Op == token.SUB && (lit.Kind == token.INT || lit.Kind == token.FLOAT) {
+			return "-" + lit.Value, true
+		}
+	}
+	return "", false
+}
+
+// SetDefaults sets the CodeDefault of each field in structs that has a value in defaults (as returned by
+// ParseDefaults), and also its Default if it doesn't have a Default annotation
+func SetDefaults(structs []Struct, defaults map[string]map[string]string) {
+	for _, str := range structs {
+		for f := range str.Fields {
+			field := &str.Fields[f]
+			val, ok := defaults[str.Name][field.Name]
+			if !ok {
+				continue
+			}
+			field.CodeDefault = val
+			if field.Default == "" {
+				field.Default = val
+			}
+		}
+	}
+}
+
+// InferDefaults sets the InferredDefault of each bool, numeric, and string field in structs without a default to the
+// zero value of its type, which is what gochan uses if the field is left out of gochan.json. Required fields are
+// left without one, since they can't be left out
+func InferDefaults(structs []Struct) {
+	for _, str := range structs {
+		for f := range str.Fields {
+			field := &str.Fields[f]
+			if field.Default != "" || len(field.PlatformDefaults) > 0 || field.Required {
+				continue
+			}
+			typ := structTypeName(field)
+			switch {
+			case typ == "bool":
+				field.InferredDefault = "false"
+			case isNumericType(typ):
+				field.InferredDefault = "0"
+			case typ == "string":
+				field.InferredDefault = `""`
+			}
+		}
+	}
+}
+
+// defaultsDiffer returns true if the field's Default annotation doesn't match the value assigned to it in code.
+// Like ValidateDefaults, only the first word of the annotation is compared for bool and numeric types
+func defaultsDiffer(field *Field) bool {
+	if field.CodeDefault == "" || field.Default == field.CodeDefault {
+		return false
+	}
+	typ := structTypeName(field)
+	if typ == "bool" || isNumericType(typ) {
+		def, _, _ := strings.Cut(strings.TrimSpace(field.Default), " ")
+		return def != field.CodeDefault
+	}
+	return true
+}
+
+// ParseConstants parses the non-test Go files in dir and returns the values of the package-level constants declared
+// in them with literal values (strings, numbers, and true or false) or iota, keyed by name, with strings unquoted.
+// Constants set to other expressions are left out
+func ParseConstants(dir string) (map[string]string, error) {
+	constants := make(map[string]string)
+	err := forEachConst(dir, func(name *ast.Ident, _ string, value ast.Expr, iota int, _ *ast.CommentGroup) {
+		if ident, ok := value.(*ast.Ident); ok && ident.Name == "iota" {
+			constants[name.Name] = strconv.Itoa(iota)
+		} else if val, ok := literalValue(value); ok {
+			constants[name.Name] = val
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return constants, nil
+}
+
+// ResolveConstants replaces the Default of each field in structs that is the name of one of the given constants
+// (as returned by ParseConstants), e.g. "Default: DefaultPort", with the constant's value, and sets its
+// DefaultConstant to the name so that the tables can show both. Defaults that aren't the name of a constant are
+// left as they are
+func ResolveConstants(structs []Struct, constants map[string]string) {
+	for _, str := range structs {
+		for f := range str.Fields {
+			field := &str.Fields[f]
+			name := strings.TrimSpace(field.Default)
+			if val, ok := constants[name]; ok && name != "" {
+				field.Default = val
+				field.DefaultConstant = name
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package cfgdoc
+
+import (
+	"strings"
+	"unicode"
+)
+
+// RenderDotenv renders an example .env file with a variable for each path returned by FieldPaths that doesn't lead
+// to a struct, named after the path with opts.EnvPrefix (see EnvName) and set to the field's default, with the
+// field's doc as a comment above it. Sensitive fields are left empty
+func RenderDotenv(structs []Struct, opts Options) string {
+	structMap := structsByName(structs)
+	var builder strings.Builder
+	for _, path := range FieldPaths(structs, opts) {
+		// structs (and slices or maps of them) can't be set from a single variable, their fields have their own
+		if _, ok := structMap[referencedTypeName(structTypeName(&path.Field))]; ok {
+			continue
+		}
+		if builder.Len() > 0 {
+			builder.WriteString("\n")
+		}
+		if info := infoText(&path.Field, &opts); info != "" {
+			builder.WriteString("# " + info + "\n")
+		}
+		val := path.Field.Default
+		if path.Field.Sensitive {
+			// a default secret shouldn't end up in deployed .env files
+			val = ""
+		}
+		builder.WriteString(EnvName(opts.EnvPrefix, path.Path) + "=" + dotenvValue(val) + "\n")
+	}
+	return builder.String()
+}
+
+// EnvName returns the environment variable for a config key path like "Captcha.SiteKey", with each key in upper
+// case and an underscore before each upper case letter that follows a lower case letter or digit, separated by
+// underscores and prefixed with prefix, e.g. GOCHAN_SITE_NAME for SiteName with the prefix GOCHAN_
+func EnvName(prefix string, path string) string {
+	var builder strings.Builder
+	builder.WriteString(prefix)
+	var prev rune
+	for _, r := range path {
+		switch {
+		case r == '.':
+			r = '_'
+		case unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			builder.WriteRune('_')
+		}
+		builder.WriteRune(unicode.ToUpper(r))
+		prev = r
+	}
+	return builder.String()
+}
+
+// dotenvValue returns val as the value of a .env variable, double-quoted if it contains whitespace or characters
+// with special meaning to dotenv parsers or shells
+func dotenvValue(val string) string {
+	if !strings.ContainsAny(val, " \t\n\"'`#$\\") {
+		return val
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`", "\n", `\n`)
+	return `"` + replacer.Replace(val) + `"`
+}
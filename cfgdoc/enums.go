@@ -0,0 +1,124 @@
+package cfgdoc
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Enum is a declared type with exported constants, e.g. the modes a config field of that type can be set to
+type Enum struct {
+	Type   string
+	Values []EnumValue
+}
+
+// EnumValue is an exported constant of an Enum's type
+type EnumValue struct {
+	Name string
+
+	// Value is the constant's value as it would be written in a config file, e.g. "exif" (with the quotes) for a
+	// string constant. Values that aren't literals or iota are shown as their Go expression
+	Value string
+
+	Doc string
+}
+
+// ParseEnums parses the non-test Go files in dir and returns the declared types that have exported constants,
+// sorted by name, with their constants in declaration order. Constants whose value is omitted repeat the type and
+// expression of the previous one in their group, like the compiler does, so iota-based groups are supported
+func ParseEnums(dir string) ([]Enum, error) {
+	enumMap := make(map[string]*Enum)
+	err := forEachConst(dir, func(name *ast.Ident, typ string, value ast.Expr, iota int, doc *ast.CommentGroup) {
+		if typ == "" || !token.IsExported(typ) || !name.IsExported() {
+			return
+		}
+		enum, ok := enumMap[typ]
+		if !ok {
+			enum = &Enum{Type: typ}
+			enumMap[typ] = enum
+		}
+		enum.Values = append(enum.Values, EnumValue{
+			Name:  name.Name,
+			Value: constValue(value, iota),
+			Doc:   strings.TrimSpace(doc.Text()),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	enums := make([]Enum, 0, len(enumMap))
+	for _, enum := range enumMap {
+		enums = append(enums, *enum)
+	}
+	slices.SortFunc(enums, func(a, b Enum) int {
+		return strings.Compare(a.Type, b.Type)
+	})
+	return enums, nil
+}
+
+// forEachConst calls fn for each package-level constant declared in the non-test Go files in dir, in declaration
+// order, with the name of its type if it is declared with an unqualified one (e.g. "StripMetadataMode" or "int",
+// but not "geoip.HandlerType"), the expression it is set to, its index in its group (the value of iota), and its doc
+// (see specDoc), or its trailing comment if it has none. Constants whose value is omitted repeat the type and
+// expression of the previous one in their group, like the compiler does
+func forEachConst(dir string, fn func(name *ast.Ident, typ string, value ast.Expr, iota int, doc *ast.CommentGroup)) error {
+	paths, err := goFiles(dir, nil)
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		file, err := parseFile(fset, path, path)
+		if err != nil {
+			return err
+		}
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.CONST {
+				continue
+			}
+			var typ string
+			var values []ast.Expr
+			for iota, spec := range genDecl.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+				if len(valueSpec.Values) > 0 {
+					typ = ""
+					if ident, ok := valueSpec.Type.(*ast.Ident); ok {
+						typ = ident.Name
+					}
+					values = valueSpec.Values
+				}
+				for n, name := range valueSpec.Names {
+					if n < len(values) {
+						doc := specDoc(genDecl, valueSpec.Doc)
+						if doc == nil {
+							doc = valueSpec.Comment
+						}
+						fn(name, typ, values[n], iota, doc)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// constValue returns the value of a constant declared with the given expression at the given index in its group
+func constValue(expr ast.Expr, iota int) string {
+	if ident, ok := expr.(*ast.Ident); ok && ident.Name == "iota" {
+		return strconv.Itoa(iota)
+	}
+	if lit, ok := expr.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+		if val, err := strconv.Unquote(lit.Value); err == nil {
+			return strconv.Quote(val)
+		}
+	}
+	if val, ok := literalValue(expr); ok {
+		return val
+	}
+	return types.ExprString(expr)
+}
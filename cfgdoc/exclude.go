@@ -0,0 +1,36 @@
+package cfgdoc
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Exclude returns the given structs without the structs and fields named in exclusions, in the form "StructName"
+// or "StructName.FieldName". It returns an error if an exclusion doesn't match any struct or field, since that
+// usually means it was misspelled or the struct was renamed
+func Exclude(structs []Struct, exclusions []string) ([]Struct, error) {
+	used := make(map[string]bool)
+	filtered := make([]Struct, 0, len(structs))
+	for _, str := range structs {
+		if slices.Contains(exclusions, str.Name) {
+			used[str.Name] = true
+			continue
+		}
+		fields := make([]Field, 0, len(str.Fields))
+		for _, field := range str.Fields {
+			if name := str.Name + "." + field.Name; field.Name != "" && slices.Contains(exclusions, name) {
+				used[name] = true
+				continue
+			}
+			fields = append(fields, field)
+		}
+		str.Fields = fields
+		filtered = append(filtered, str)
+	}
+	for _, exclusion := range exclusions {
+		if !used[exclusion] {
+			return nil, fmt.Errorf("excluded struct or field %s not found", exclusion)
+		}
+	}
+	return filtered, nil
+}
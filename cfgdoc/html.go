@@ -0,0 +1,192 @@
+package cfgdoc
+
+import (
+	"html"
+	"strconv"
+	"strings"
+)
+
+const (
+	htmlDocumentHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>gochan configuration</title>
+<style>
+table.cfgdoc { border-collapse: collapse; }
+table.cfgdoc th, table.cfgdoc td { border: 1px solid #888; padding: 2px 6px; text-align: left; vertical-align: top; }
+table.cfgdoc td.board-option-yes { background-color: #cfc; }
+table.cfgdoc td.board-option-no { background-color: #fcc; }
+table.cfgdoc tr.cfgdoc-struct td { background-color: #eee; }
+</style>
+</head>
+<body>
+<h1>Configuration</h1>
+`
+	htmlDocumentFooter = "</body>\n</html>"
+)
+
+// anyField returns true if hasValue returns true for any of the documented, non-deprecated fields in the given
+// structs, for deciding whether to show optional columns
+func anyField(hasValue func(*Field) bool, strs ...Struct) bool {
+	for _, str := range strs {
+		for _, field := range str.Fields {
+			if !field.IsDeprecated() && hasValue(&field) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// boardOptionColumn returns true if a table of the given structs gets a Board option column, which is only the case
+// if some of its fields are board options and others aren't. The composite structs (if named is false) are taken
+// together, so that they all get the column if any of them differ, even when each one has its own table
+func boardOptionColumn(named bool, opts *Options, strs ...Struct) bool {
+	if !named {
+		strs = nil
+		for _, structName := range opts.CompositeStructs {
+			if str, ok := opts.structs[structName]; ok {
+				strs = append(strs, str)
+			}
+		}
+	}
+	var boardOptions, others bool
+	for _, str := range strs {
+		for _, field := range str.Fields {
+			if field.IsDeprecated() {
+				continue
+			}
+			if str.IsBoardOption(&field, opts.BoardStructs) {
+				boardOptions = true
+			} else {
+				others = true
+			}
+		}
+	}
+	return boardOptions && others
+}
+
+// noDocumentedFields is written instead of a table for structs with no documented, non-deprecated fields
+const noDocumentedFields = "(no documented fields)"
+
+// hasDocumentedFields returns true if the given structs have any documented, non-deprecated fields to put in a table
+func hasDocumentedFields(strs ...Struct) bool {
+	return anyField(func(*Field) bool { return true }, strs...)
+}
+
+// structsAsHTMLTable writes a single table containing the fields of all of the given structs, or a note saying
+// there are none. The table gets the columns of fieldColumns that newFieldTable returns, and if named is false and
+// opts.CompositeStructDocs is set, each struct's rows are preceded by a row with its name and doc
+func structsAsHTMLTable(builder *strings.Builder, named bool, opts *Options, strs ...Struct) {
+	if !hasDocumentedFields(strs...) {
+		builder.WriteString("<p>" + noDocumentedFields + "</p>\n")
+		return
+	}
+	flat := !named && opts.FlatSort == FieldSortName
+	var flatFields []structField
+	if flat {
+		flatFields = flatSortedFields(strs)
+	}
+	table := newFieldTable(opts, named, flat, strs...)
+	headers := table.headers()
+	builder.WriteString("<table class=\"cfgdoc\">\n<thead>\n<tr>")
+	for _, header := range headers {
+		builder.WriteString("<th>" + html.EscapeString(header) + "</th>")
+	}
+	builder.WriteString("</tr>\n</thead>\n<tbody>\n")
+
+	writeRow := func(str *Struct, field *Field) {
+		builder.WriteString("<tr")
+		if opts.FieldAnchors {
+			builder.WriteString(" id=\"" + FieldAnchor(str.Name, field.Name) + "\"")
+		}
+		builder.WriteString(">" + table.htmlCells(str, field))
+		builder.WriteString("<td>" + html.EscapeString(infoText(field, opts)) + "</td></tr>\n")
+	}
+
+	if flat {
+		for _, row := range flatFields {
+			writeRow(row.str, row.field)
+		}
+	} else {
+		for s, str := range strs {
+			if !named && opts.CompositeStructDocs && hasDocumentedFields(str) {
+				builder.WriteString("<tr class=\"cfgdoc-struct\"><td colspan=\"" + strconv.Itoa(len(headers)) + "\"><b>" + html.EscapeString(str.Name) + "</b>")
+				if str.Doc != "" {
+					builder.WriteString(": " + html.EscapeString(strings.Join(strings.Fields(str.Doc), " ")))
+				}
+				builder.WriteString("</td></tr>\n")
+			}
+			for f := range str.Fields {
+				if !str.Fields[f].IsDeprecated() {
+					writeRow(&strs[s], &str.Fields[f])
+				}
+			}
+		}
+	}
+	builder.WriteString("</tbody>\n</table>\n")
+	for _, group := range exclusiveGroups(strs...) {
+		builder.WriteString("<p class=\"cfgdoc-group\"><b>" + html.EscapeString(group.Name) + "</b>: set one of " +
+			html.EscapeString(joinOr(group.Fields, "")) + "</p>\n")
+	}
+}
+
+// namedStructAsHTML writes an <h2> heading, the struct's doc (if any), and its table
+func namedStructAsHTML(str *Struct, builder *strings.Builder, opts *Options) {
+	builder.WriteString("<h2>" + html.EscapeString(str.Name) + "</h2>\n")
+	if str.Doc != "" {
+		builder.WriteString("<p>" + html.EscapeString(strings.Join(strings.Fields(str.Doc), " ")) + "</p>\n")
+	}
+	structsAsHTMLTable(builder, true, opts, *str)
+}
+
+// RenderHTML renders the combined table of opts.CompositeStructs and the tables of opts.NamedStructs as HTML. If
+// opts.Standalone is true, the tables are wrapped in a complete HTML document
+func RenderHTML(structs []Struct, opts Options) string {
+	structMap := tableStructs(structs, &opts)
+	opts.structs = structMap
+	var builder strings.Builder
+	if opts.Standalone {
+		builder.WriteString(htmlDocumentHeader)
+	}
+
+	compositeStructs := compositeStructList(structMap, &opts)
+	if len(compositeStructs) > 0 {
+		structsAsHTMLTable(&builder, false, &opts, compositeStructs...)
+	}
+
+	for _, structName := range namedStructNames(structMap, &opts) {
+		str, ok := structMap[structName]
+		if !ok {
+			continue
+		}
+		namedStructAsHTML(&str, &builder, &opts)
+	}
+
+	enumsAsHTML(&builder, &opts)
+
+	if opts.Standalone {
+		builder.WriteString(htmlDocumentFooter)
+	}
+	return builder.String()
+}
+
+// enumsAsHTML writes a Constants heading and a table with a row for each value of opts.Enums, if there are any
+func enumsAsHTML(builder *strings.Builder, opts *Options) {
+	if len(opts.Enums) == 0 {
+		return
+	}
+	builder.WriteString("<h2>Constants</h2>\n<table class=\"cfgdoc\">\n<thead>\n<tr><th>Type</th><th>Value</th><th>Info</th></tr>\n</thead>\n<tbody>\n")
+	for _, enum := range opts.Enums {
+		for _, val := range enum.Values {
+			info := val.Doc
+			if info == "" {
+				info = val.Name
+			}
+			builder.WriteString("<tr><td>" + html.EscapeString(enum.Type) + "</td><td>" + html.EscapeString(val.Value) +
+				"</td><td>" + html.EscapeString(strings.Join(strings.Fields(info), " ")) + "</td></tr>\n")
+		}
+	}
+	builder.WriteString("</tbody>\n</table>\n")
+}
@@ -0,0 +1,216 @@
+package cfgdoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RenderExampleJSON renders an example configuration as a JSON object with one key per non-deprecated field of
+// opts.CompositeStructs, keyed by its JSON name and set to the field's default value (or the zero value of its type
+// if it has none). Fields whose type is a parsed struct are written as nested objects of that struct's fields,
+// including the fields of the structs it embeds, like in RenderYAML
+func RenderExampleJSON(structs []Struct, opts Options) string {
+	structMap := structsByName(structs)
+	var fields []Field
+	for _, structName := range opts.CompositeStructs {
+		if str, ok := structMap[structName]; ok {
+			// embedded structs are listed in opts.CompositeStructs themselves
+			fields = append(fields, str.Fields...)
+		}
+	}
+	var builder bytes.Buffer
+	jsonObject(&builder, fields, structMap, &opts, opts.CompositeStructs)
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, builder.Bytes(), "", "\t"); err != nil {
+		// jsonValue only returns valid JSON values
+		panic(err)
+	}
+	return indented.String()
+}
+
+// jsonObject writes a JSON object with a member for each of the given non-deprecated, exported fields, keyed by its
+// JSON name. A field whose key is already written (e.g. one declared in more than one composite struct) is only
+// written once. parents holds the structs being expanded, so that a struct that contains itself isn't expanded
+// forever
+func jsonObject(builder *bytes.Buffer, fields []Field, structMap map[string]Struct, opts *Options, parents []string) {
+	builder.WriteString("{")
+	written := make(map[string]bool)
+	for _, field := range fields {
+		if field.Name == "" || field.IsDeprecated() || field.Unexported || written[field.Key()] {
+			// unexported fields can't be set in JSON
+			continue
+		}
+		if len(written) > 0 {
+			builder.WriteString(",")
+		}
+		written[field.Key()] = true
+		builder.WriteString(jsonString(field.Key()) + ":")
+
+		typ := structTypeName(&field)
+		if str, ok := structMap[typ]; ok && !slices.Contains(parents, typ) && hasDocumentedFields(str) {
+			// the fields of structs embedded in str are at the same level as its own fields
+			jsonObject(builder, promotedFields(&str, structMap, parents), structMap, opts, append(parents, typ))
+			continue
+		}
+		builder.WriteString(jsonValue(&field, structMap, opts.DurationFormat))
+	}
+	builder.WriteString("}")
+}
+
+// JSONCMembers returns the non-deprecated fields of str as the members of a JSONC object, one per line prefixed
+// with indent, keyed by their JSON name. Each field is set to its Example annotation if it has one and isn't
+// sensitive, or to the same value as in RenderExampleJSON otherwise, with durations in the given format. Fields
+// with an Optional annotation or Values get a comment saying so, e.g. "// optional, one of "de", "en""
+func JSONCMembers(str *Struct, indent string, durations DurationFormat) string {
+	var fields []Field
+	for _, field := range str.Fields {
+		if field.Name != "" && !field.IsDeprecated() && !field.Unexported {
+			fields = append(fields, field)
+		}
+	}
+	var builder strings.Builder
+	for f, field := range fields {
+		val := field.Example
+		if val == "" || field.Sensitive {
+			val = jsonValue(&field, nil, durations)
+		} else if !json.Valid([]byte(val)) {
+			val = jsonString(val)
+		}
+		builder.WriteString(indent + jsonString(field.Key()) + ": " + val)
+		if f < len(fields)-1 {
+			builder.WriteString(",")
+		}
+		var notes []string
+		if field.Optional {
+			notes = append(notes, "optional")
+		}
+		if len(field.Values) > 0 {
+			quoted := make([]string, len(field.Values))
+			for v, value := range field.Values {
+				quoted[v] = jsonString(value)
+			}
+			notes = append(notes, "one of "+strings.Join(quoted, ", "))
+		}
+		if len(notes) > 0 {
+			builder.WriteString(" // " + strings.Join(notes, ", "))
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// jsonValue returns the field's default value as a JSON value according to its type, e.g. 8080 for an int or
+// "gochan" for a string, and durations in the given format. Fields without a default (or with one that isn't valid
+// for the type) get the type's zero value, with [] and {} as placeholders for slices, maps, and structs. Sensitive
+// fields always get the zero value, so that a default secret isn't published in examples
+func jsonValue(field *Field, structMap map[string]Struct, durations DurationFormat) string {
+	if field.Sensitive {
+		placeholder := *field
+		placeholder.Default = ""
+		field = &placeholder
+	}
+	typ := structTypeName(field)
+	def := strings.TrimSpace(field.Default)
+	switch {
+	case strings.HasPrefix(typ, "["):
+		if strings.HasPrefix(def, "[") && json.Valid([]byte(def)) {
+			return def
+		}
+		return "[]"
+	case strings.HasPrefix(typ, "map["):
+		if strings.HasPrefix(def, "{") && json.Valid([]byte(def)) {
+			return def
+		}
+		return "{}"
+	case typ == "any":
+		if json.Valid([]byte(def)) {
+			return def
+		}
+		if def != "" {
+			return jsonString(def)
+		}
+		return "null"
+	case typ == "time.Duration":
+		return jsonDuration(def, durations)
+	case typ == "bool":
+		if val, err := strconv.ParseBool(def); err == nil {
+			return strconv.FormatBool(val)
+		}
+		return "false"
+	case isNumericType(typ):
+		if _, err := strconv.ParseFloat(def, 64); err == nil && json.Valid([]byte(def)) {
+			return def
+		}
+		if def != "" {
+			// e.g. a value with units that the field is parsed from as a string
+			return jsonString(def)
+		}
+		return "0"
+	}
+	if _, ok := structMap[typ]; ok {
+		return "{}"
+	}
+	return jsonString(field.Default)
+}
+
+// malformedJSONDefault returns the kind of JSON value ("array" or "object") that the default of a slice or map field
+// is written as, and true if it doesn't parse as one. jsonValue would silently replace it with [] or {}
+func malformedJSONDefault(field *Field) (string, bool) {
+	typ := structTypeName(field)
+	def := strings.TrimSpace(field.Default)
+	var kind string
+	switch {
+	case strings.HasPrefix(typ, "[") && strings.HasPrefix(def, "["):
+		kind = "array"
+	case strings.HasPrefix(typ, "map[") && strings.HasPrefix(def, "{"):
+		kind = "object"
+	default:
+		return "", false
+	}
+	return kind, !json.Valid([]byte(def))
+}
+
+// jsonDuration returns the default of a time.Duration field in the given format. The default can be written either
+// way, e.g. 90s or 90000000000. Defaults that are neither are written as strings, like for numeric types
+func jsonDuration(def string, durations DurationFormat) string {
+	var d time.Duration
+	if ns, err := strconv.ParseInt(def, 10, 64); err == nil {
+		d = time.Duration(ns)
+	} else if d, err = time.ParseDuration(def); err == nil {
+		if durations == DurationString {
+			// kept as it was written, rather than e.g. 1h30m0s for 90m
+			return jsonString(def)
+		}
+	} else if def != "" {
+		return jsonString(def)
+	}
+	if durations == DurationString {
+		return jsonString(d.String())
+	}
+	return strconv.FormatInt(int64(d), 10)
+}
+
+// jsonString returns s as a JSON string, without escaping HTML characters like json.Marshal does
+func jsonString(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// isNumericType returns true if typ is one of Go's integer or floating point types
+func isNumericType(typ string) bool {
+	switch typ {
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+		"float32", "float64", "byte", "rune":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package cfgdoc
+
+import (
+	"slices"
+	"strings"
+)
+
+// FieldPath is a non-deprecated, exported field reached from the top level of the configuration, and the dotted
+// path of the JSON keys leading to it
+type FieldPath struct {
+	// Path is the keys leading to the field, ending with its own, e.g. "Captcha.SiteKey". Fields reached through an
+	// embedded struct don't add a key for it
+	Path string
+
+	// Struct is the name of the struct the field is declared in
+	Struct string
+
+	Field Field
+}
+
+// Keys returns the keys in the path
+func (p *FieldPath) Keys() []string {
+	return strings.Split(p.Path, ".")
+}
+
+// FieldPaths returns the non-deprecated, exported fields of opts.CompositeStructs, which are at the top level of the
+// configuration, followed by the fields of the parsed structs used as their types (or pointed to by them), and so
+// on. The fields of an embedded struct are at the same level as the embedding struct's own fields, like
+// encoding/json treats them, while a field whose type is a struct adds a level to the path of that struct's
+// fields. The fields of slice elements and map values have no fixed path, so they aren't included. A path that is
+// reached more than once (e.g. through a composite struct that is also embedded in another) is only included once
+func FieldPaths(structs []Struct, opts Options) []FieldPath {
+	structMap := structsByName(structs)
+	var paths []FieldPath
+	seen := make(map[string]bool)
+	var walk func(str *Struct, prefix string, parents []string)
+	walk = func(str *Struct, prefix string, parents []string) {
+		for _, field := range str.Fields {
+			if field.Name == "" || field.IsDeprecated() || field.Unexported {
+				continue
+			}
+			path := prefix + field.Key()
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			paths = append(paths, FieldPath{Path: path, Struct: str.Name, Field: field})
+			if nested, ok := structMap[structTypeName(&field)]; ok && !slices.Contains(parents, nested.Name) {
+				walk(&nested, path+".", append(parents, nested.Name))
+			}
+		}
+		for _, embedded := range str.Embedded {
+			if nested, ok := structMap[embedded]; ok && !slices.Contains(parents, embedded) {
+				walk(&nested, prefix, append(parents, embedded))
+			}
+		}
+	}
+	for _, structName := range opts.CompositeStructs {
+		if str, ok := structMap[structName]; ok {
+			walk(&str, "", []string{structName})
+		}
+	}
+	return paths
+}
+
+// ExpandComposites returns the names of the composite structs, each followed by the parsed structs it embeds (by
+// value or by pointer) that aren't already in names, and the structs they embed, and so on. Their fields are at the
+// top level of the configuration too, so they belong in Options.CompositeStructs with the struct embedding them
+func ExpandComposites(structs []Struct, names []string) []string {
+	structMap := structsByName(structs)
+	expanded := make([]string, 0, len(names))
+	var add func(name string)
+	add = func(name string) {
+		expanded = append(expanded, name)
+		for _, embedded := range structMap[name].Embedded {
+			if _, ok := structMap[embedded]; ok && !slices.Contains(names, embedded) && !slices.Contains(expanded, embedded) {
+				add(embedded)
+			}
+		}
+	}
+	for _, name := range names {
+		if !slices.Contains(expanded, name) {
+			add(name)
+		}
+	}
+	return expanded
+}
+
+// promotedFields returns the fields of str that aren't embedded structs, followed by the fields of its embedded
+// structs (and theirs), which are at the same level in JSON. parents holds the structs being expanded, so that a
+// struct that embeds itself isn't expanded forever
+func promotedFields(str *Struct, structMap map[string]Struct, parents []string) []Field {
+	var fields []Field
+	for _, field := range str.Fields {
+		if field.Name != "" {
+			fields = append(fields, field)
+		}
+	}
+	for _, embedded := range str.Embedded {
+		if nested, ok := structMap[embedded]; ok && !slices.Contains(parents, embedded) {
+			fields = append(fields, promotedFields(&nested, structMap, append(parents, embedded))...)
+		}
+	}
+	return fields
+}
+
+// structTypeName returns the type of a field, or the type that it resolves to if it is a declared non-struct type
+// or alias, without the * of a pointer type, for looking up the parsed struct it may be and checking its values
+func structTypeName(field *Field) string {
+	if field.Underlying != "" {
+		return derefType(field.Underlying)
+	}
+	return derefType(field.Type)
+}
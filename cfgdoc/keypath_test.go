@@ -0,0 +1,101 @@
+package cfgdoc
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"testing"
+)
+
+// nestingStructs returns a composite struct that embeds another composite struct, whose fields are at the top
+// level, and has a field of a named struct type, whose fields are nested under the field's key
+func nestingStructs() ([]Struct, Options) {
+	structs := []Struct{
+		{
+			Name: "SystemConfig",
+			Fields: []Field{
+				{Name: "ListenAddress", Type: "string", Default: "0.0.0.0"},
+				{Name: "Verbose", Type: "bool", JSONName: "DebugMode"},
+				{Name: "Captcha", Type: "CaptchaConfig"},
+			},
+			Embedded: []string{"SiteConfig"},
+		},
+		{
+			Name: "SiteConfig",
+			Fields: []Field{
+				{Name: "SiteName", Type: "string", Default: "Gochan"},
+				{Name: "Limit", Type: "int", JSONName: "threads"},
+			},
+		},
+		{
+			Name: "CaptchaConfig",
+			Fields: []Field{
+				{Name: "Type", Type: "string", Default: "hcaptcha"},
+				{Name: "Secret", Type: "string", JSONName: "secretKey"},
+			},
+		},
+	}
+	return structs, Options{CompositeStructs: []string{"SystemConfig", "SiteConfig"}}
+}
+
+func TestFieldPathsNesting(t *testing.T) {
+	structs, opts := nestingStructs()
+	var paths []string
+	for _, path := range FieldPaths(structs, opts) {
+		paths = append(paths, path.Path)
+	}
+	expected := []string{
+		"ListenAddress", "DebugMode", "Captcha", "Captcha.Type", "Captcha.secretKey", // SystemConfig's own fields
+		"SiteName", "threads", // SiteConfig's fields, embedded at the top level and not repeated as a composite
+	}
+	if !slices.Equal(paths, expected) {
+		t.Errorf("expected paths %q, got %q", expected, paths)
+	}
+}
+
+func TestRenderExampleJSONNesting(t *testing.T) {
+	structs, opts := nestingStructs()
+	var example map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(RenderExampleJSON(structs, opts)), &example); err != nil {
+		t.Fatalf("example isn't valid JSON: %s", err)
+	}
+	var keys []string
+	for key := range example {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	// the embedded struct's fields are at the top level, while the nested struct's fields are in the Captcha object
+	expected := []string{"Captcha", "DebugMode", "ListenAddress", "SiteName", "threads"}
+	if !slices.Equal(keys, expected) {
+		t.Errorf("expected keys %q, got %q", expected, keys)
+	}
+	if string(example["SiteName"]) != `"Gochan"` {
+		t.Errorf(`expected SiteName to be "Gochan", got %s`, example["SiteName"])
+	}
+	var captcha map[string]string
+	if err := json.Unmarshal(example["Captcha"], &captcha); err != nil {
+		t.Fatalf("expected Captcha to be an object of strings, got %s", example["Captcha"])
+	}
+	if expected := map[string]string{"Type": "hcaptcha", "secretKey": ""}; !maps.Equal(captcha, expected) {
+		t.Errorf("expected Captcha to be %v, got %v", expected, captcha)
+	}
+}
+
+func TestExpandComposites(t *testing.T) {
+	structs, _ := nestingStructs()
+	tests := []struct {
+		names    []string
+		expected []string
+	}{
+		// SiteConfig is embedded in SystemConfig, so it follows it
+		{names: []string{"SystemConfig"}, expected: []string{"SystemConfig", "SiteConfig"}},
+		// structs that are already composites aren't added again
+		{names: []string{"SiteConfig", "SystemConfig"}, expected: []string{"SiteConfig", "SystemConfig"}},
+		{names: []string{"CaptchaConfig"}, expected: []string{"CaptchaConfig"}},
+	}
+	for _, test := range tests {
+		if got := ExpandComposites(structs, test.names); !slices.Equal(got, test.expected) {
+			t.Errorf("ExpandComposites(%q) = %q, expected %q", test.names, got, test.expected)
+		}
+	}
+}
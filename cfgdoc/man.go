@@ -0,0 +1,61 @@
+package cfgdoc
+
+import (
+	"slices"
+	"strings"
+)
+
+// RenderMan renders a CONFIGURATION section for a man page, with a .SS subsection for each of
+// opts.CompositeStructs and opts.NamedStructs and a .TP entry for each of their non-deprecated fields
+func RenderMan(structs []Struct, opts Options) string {
+	structMap := tableStructs(structs, &opts)
+	var builder strings.Builder
+	builder.WriteString(".SH CONFIGURATION\n")
+	for _, structName := range slices.Concat(opts.CompositeStructs, namedStructNames(structMap, &opts)) {
+		str, ok := structMap[structName]
+		if !ok {
+			continue
+		}
+		builder.WriteString(".SS " + roffText(str.Name) + "\n")
+		if doc := strings.Join(strings.Fields(str.Doc), " "); doc != "" {
+			builder.WriteString(roffText(doc) + "\n")
+		}
+		for _, field := range str.Fields {
+			if field.IsDeprecated() {
+				continue
+			}
+			builder.WriteString(".TP\n.B " + roffText(field.Name) + "\n")
+			builder.WriteString("Type: " + roffText(field.TypeText(opts.ResolveAliases)))
+			if field.Required {
+				builder.WriteString(", required")
+			}
+			if field.DefaultText() != "" {
+				builder.WriteString(", default: " + roffText(field.DefaultText()))
+			}
+			if str.IsBoardOption(&field, opts.BoardStructs) {
+				builder.WriteString(", board option")
+			}
+			if len(field.Platforms) > 0 {
+				builder.WriteString(", platform: " + roffText(field.PlatformText()))
+			}
+			builder.WriteString("\n.br\n")
+			if info := infoText(&field, &opts); info != "" {
+				builder.WriteString(roffText(info) + "\n")
+			}
+		}
+	}
+	return builder.String()
+}
+
+// roffText escapes backslashes in s and prevents each line from being read as a roff request if it starts with a
+// control character (. or ')
+func roffText(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	lines := strings.Split(s, "\n")
+	for l, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[l] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
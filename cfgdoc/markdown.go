@@ -0,0 +1,1013 @@
+package cfgdoc
+
+import (
+	"go/token"
+	"html"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// writeMarkdownRow writes a table row with the given cells separated by pipes, padding every cell but the last
+// to the width of its column on the side given by its alignment in aligns (or after it if aligns is nil), with
+// centered cells padded on both sides. If minimal is true, the cells are separated by " | " without padding instead
+func writeMarkdownRow(builder *strings.Builder, widths []int, aligns []columnAlign, minimal bool, cells ...string) {
+	if minimal {
+		builder.WriteString(strings.Join(cells, " | ") + "\n")
+		return
+	}
+	for c, cell := range cells {
+		if c > 0 {
+			builder.WriteRune('|')
+		}
+		if c == len(cells)-1 {
+			builder.WriteString(cell)
+			break
+		}
+		padding := max(widths[c]-utf8.RuneCountInString(cell), 0)
+		before := 0
+		if aligns != nil && padding > 0 {
+			// the last column of padding separates the cell from the next one
+			switch aligns[c] {
+			case alignCenter:
+				before = (padding - 1) / 2
+			case alignRight:
+				before = padding - 1
+			}
+		}
+		builder.WriteString(strings.Repeat(" ", before) + cell + strings.Repeat(" ", padding-before))
+	}
+	builder.WriteRune('\n')
+}
+
+// writeMarkdownDivider writes the row separating a table's header from its data rows, with a dash for each column
+// of padding, or "---" for each column if minimal is true. If aligns isn't nil, a colon replaces the first dash of
+// left-aligned and centered columns and the last dash of right-aligned and centered columns, e.g. ":---", ":--:",
+// and "---:"
+func writeMarkdownDivider(builder *strings.Builder, widths []int, aligns []columnAlign, minimal bool) {
+	for c, width := range widths {
+		if c > 0 {
+			if minimal {
+				builder.WriteString(" | ")
+			} else {
+				builder.WriteRune('|')
+			}
+		}
+		if minimal {
+			width = 3
+			if aligns != nil {
+				width = 4
+			}
+		}
+		divider := []byte(strings.Repeat("-", width))
+		if aligns != nil {
+			if aligns[c] != alignRight {
+				divider[0] = ':'
+			}
+			if aligns[c] != alignLeft {
+				divider[len(divider)-1] = ':'
+			}
+		}
+		builder.Write(divider)
+	}
+	builder.WriteRune('\n')
+}
+
+// markdownCellText collapses runs of whitespace (including newlines) in s into single spaces and escapes pipe
+// characters so that the text can't break out of its table cell
+func markdownCellText(s string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(s), " "), "|", "\\|")
+}
+
+// markdownFieldCell returns the Field cell of a field of str, preceded by an empty anchor to link to it if
+// opts.FieldAnchors is set
+func markdownFieldCell(str *Struct, field *Field, opts *Options) string {
+	if anchor := FieldAnchor(str.Name, field.Name); opts.FieldAnchors || opts.linkedAnchors[anchor] {
+		return `<a id="` + anchor + `"></a>` + field.Name
+	}
+	return field.Name
+}
+
+// markdownInfoText returns the Info cell of a field. A doc comment with more than one paragraph, list items, or
+// indented (code) lines keeps its structure, with each paragraph, list item, and indented line on its own line
+// separated by <br> (and paragraphs by an empty line), while other doc comments are joined into one line
+func markdownInfoText(field *Field, opts *Options) string {
+	if !structuredDoc(field.Doc) {
+		return markdownCellText(infoText(field, opts))
+	}
+	var lines []string
+	var current string
+	flush := func() {
+		if current != "" {
+			lines = append(lines, markdownCellText(current))
+			current = ""
+		}
+	}
+	for _, line := range strings.Split(strings.TrimSpace(linkedDoc(field, opts)), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			flush()
+			if len(lines) > 0 && lines[len(lines)-1] != "" {
+				lines = append(lines, "")
+			}
+		case isListItem(trimmed):
+			flush()
+			current = trimmed
+		case trimmed != line:
+			flush()
+			lines = append(lines, markdownCellText(trimmed))
+		case current == "":
+			current = trimmed
+		default:
+			current += " " + trimmed
+		}
+	}
+	flush()
+	info := strings.Join(lines, "<br>")
+	if annotations := markdownCellText(infoAnnotations(field, opts)); annotations != "" {
+		info += " " + annotations
+	}
+	return info
+}
+
+// structuredDoc returns true if a doc comment has more than one paragraph, or any list items or indented lines
+func structuredDoc(doc string) bool {
+	for _, line := range strings.Split(strings.TrimSpace(doc), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed != line || isListItem(trimmed) {
+			return true
+		}
+	}
+	return false
+}
+
+// isListItem returns true if line (without leading whitespace) starts with a list marker, e.g. "- ", "* ", or "1. "
+func isListItem(line string) bool {
+	if strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") || strings.HasPrefix(line, "+ ") {
+		return true
+	}
+	digits := len(line) - len(strings.TrimLeft(line, "0123456789"))
+	return digits > 0 && (strings.HasPrefix(line[digits:], ". ") || strings.HasPrefix(line[digits:], ") "))
+}
+
+// wrapMarkdownCell soft-wraps the text of a table cell (as returned by markdownCellText) into lines of at most
+// width characters joined by <br>, so that the cell is rendered on multiple lines without breaking the table.
+// Inline code spans are never split, and words longer than width are put on a line of their own. A width of 0 or
+// less leaves the text unwrapped
+func wrapMarkdownCell(text string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	if strings.Contains(text, "<br>") {
+		// the lines of a structured doc comment (see markdownInfoText) are wrapped separately
+		lines := strings.Split(text, "<br>")
+		for l, line := range lines {
+			lines[l] = wrapMarkdownCell(line, width)
+		}
+		return strings.Join(lines, "<br>")
+	}
+	return strings.Join(wrapWords(text, width), "<br>")
+}
+
+// wrapWords splits text into lines of at most width characters at spaces. Inline code spans are never split, and
+// words longer than width are put on a line of their own
+func wrapWords(text string, width int) []string {
+	// join the words of each inline code span into a single word
+	var words []string
+	inCode := false
+	for _, word := range strings.Fields(text) {
+		if inCode {
+			words[len(words)-1] += " " + word
+		} else {
+			words = append(words, word)
+		}
+		if strings.Count(word, "`")%2 == 1 {
+			inCode = !inCode
+		}
+	}
+
+	var lines []string
+	var line string
+	for _, word := range words {
+		switch {
+		case line == "":
+			line = word
+		case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	return append(lines, line)
+}
+
+// FieldAnchor returns the id that a field's table row or anchor is given with Options.FieldAnchors, its struct's
+// name and its own joined by a dash in lower case, with any other characters than letters, digits, dashes, and
+// underscores replaced with dashes, e.g. "uploadconfig-maxfilesize" or "geoip-country-flag"
+func FieldAnchor(structName string, fieldName string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return unicode.ToLower(r)
+		}
+		return '-'
+	}, structName+"-"+fieldName)
+}
+
+// seeKey returns the key of a field's See reference in Options.seeLinks. Fields are identified by their position,
+// since the same field is rendered from copies of it
+func seeKey(field *Field, ref string) string {
+	return field.Source() + " " + ref
+}
+
+// resolveSeeLinks resolves the See references of the fields of the given rendered structs to Markdown links,
+// setting opts.seeLinks and the field anchors they link to in opts.linkedAnchors. A reference to Struct.Field or to
+// a field of the same struct links to that field, a reference to a field name declared by only one other rendered
+// struct links to that field, and a reference to a struct links to its heading. References that can't be resolved
+// are left as plain text with a warning
+func resolveSeeLinks(opts *Options, rendered []Struct) {
+	opts.seeLinks = make(map[string]string)
+	opts.linkedAnchors = make(map[string]bool)
+	renderedNames := make(map[string]bool)
+	for _, str := range rendered {
+		renderedNames[str.Name] = true
+	}
+	// the anchor of a rendered struct's heading, or of the combined table's for a composite struct. Collapsible
+	// named struct tables don't have headings
+	headingAnchor := func(name string) string {
+		composite := slices.Contains(opts.CompositeStructs, name)
+		switch {
+		case !renderedNames[name]:
+			return ""
+		case composite && opts.GroupByFile:
+			return githubSlug(name)
+		case composite:
+			if opts.CompositeHeading == "" {
+				return ""
+			}
+			return githubSlug(opts.CompositeHeading)
+		case opts.Collapsible:
+			return ""
+		}
+		return githubSlug(name)
+	}
+	fieldAnchor := func(str *Struct, name string) string {
+		for _, field := range str.Fields {
+			if field.Name == name && !field.IsDeprecated() {
+				return FieldAnchor(str.Name, name)
+			}
+		}
+		return ""
+	}
+	// a link from a field of str to an anchor in the table of the named struct, which may be in another file with
+	// RenderMarkdownFiles
+	link := func(str *Struct, ref string, structName string, anchor string) string {
+		file := opts.structFiles[structName]
+		if file == opts.structFiles[str.Name] {
+			file = ""
+		}
+		return "[" + ref + "](" + file + "#" + anchor + ")"
+	}
+	// the anchor of the field that a reference from a field of rendered[s] to Struct.Field or Field names, and the
+	// struct it is in
+	resolveField := func(s int, ref string) (string, string) {
+		if structName, fieldName, qualified := strings.Cut(ref, "."); qualified {
+			if target, ok := opts.structs[structName]; ok && renderedNames[structName] {
+				return fieldAnchor(&target, fieldName), structName
+			}
+			return "", ""
+		}
+		if anchor := fieldAnchor(&rendered[s], ref); anchor != "" {
+			return anchor, rendered[s].Name
+		}
+		var matches []int
+		for t := range rendered {
+			if fieldAnchor(&rendered[t], ref) != "" {
+				matches = append(matches, t)
+			}
+		}
+		if len(matches) == 1 {
+			return fieldAnchor(&rendered[matches[0]], ref), rendered[matches[0]].Name
+		}
+		return "", ""
+	}
+	if opts.AutoLink {
+		opts.docLinks = make(map[string]string)
+	}
+	for s := range rendered {
+		for f := range rendered[s].Fields {
+			field := &rendered[s].Fields[f]
+			for _, ref := range field.See {
+				anchor, anchorStruct := resolveField(s, ref)
+				if anchor == "" && !strings.Contains(ref, ".") {
+					if file := opts.structFiles[ref]; file == MarkdownFileName(ref) {
+						// the struct's heading is at the top of its own file
+						opts.seeLinks[seeKey(field, ref)] = "[" + ref + "](" + file + ")"
+						continue
+					} else if heading := headingAnchor(ref); heading != "" {
+						opts.seeLinks[seeKey(field, ref)] = "[" + ref + "](#" + heading + ")"
+						continue
+					}
+				}
+				if anchor == "" {
+					warnf(opts.Warnings, "See reference %q of %s.%s not found, leaving it unlinked", ref,
+						rendered[s].Name, field.Name)
+					continue
+				}
+				opts.seeLinks[seeKey(field, ref)] = link(&rendered[s], ref, anchorStruct, anchor)
+				opts.linkedAnchors[anchor] = true
+			}
+			if !opts.AutoLink {
+				continue
+			}
+			replaceDocFieldRefs(field.Doc, func(ref string) string {
+				anchor, anchorStruct := resolveField(s, ref)
+				// a field mentioning its own name isn't linked to itself
+				if anchor != "" && anchor != FieldAnchor(rendered[s].Name, field.Name) {
+					opts.docLinks[seeKey(field, ref)] = link(&rendered[s], "`"+ref+"`", anchorStruct, anchor)
+					opts.linkedAnchors[anchor] = true
+				}
+				return ""
+			})
+		}
+	}
+}
+
+// linkedDoc returns a field's doc with the references to other fields resolved by resolveSeeLinks with
+// Options.AutoLink replaced by links to them
+func linkedDoc(field *Field, opts *Options) string {
+	if opts.docLinks == nil {
+		return field.Doc
+	}
+	return replaceDocFieldRefs(field.Doc, func(ref string) string {
+		return opts.docLinks[seeKey(field, ref)]
+	})
+}
+
+// replaceDocFieldRefs returns doc with each backtick-quoted field name (Field or Struct.Field, like `SiteName`)
+// replaced by the text that replace returns for it, leaving it as it is if that is empty. Other inline code isn't
+// passed to replace, so that only names that are clearly meant as references are linked
+func replaceDocFieldRefs(doc string, replace func(ref string) string) string {
+	parts := strings.Split(doc, "`")
+	var builder strings.Builder
+	for p, part := range parts {
+		switch {
+		case p%2 == 0:
+			builder.WriteString(part)
+		case p == len(parts)-1:
+			// an unclosed backtick
+			builder.WriteString("`" + part)
+		default:
+			text := ""
+			if isFieldRef(part) {
+				text = replace(part)
+			}
+			if text == "" {
+				text = "`" + part + "`"
+			}
+			builder.WriteString(text)
+		}
+	}
+	return builder.String()
+}
+
+// isFieldRef returns true if s is a Go identifier or two identifiers separated by a dot
+func isFieldRef(s string) bool {
+	structName, fieldName, qualified := strings.Cut(s, ".")
+	if !qualified {
+		return token.IsIdentifier(s)
+	}
+	return token.IsIdentifier(structName) && token.IsIdentifier(fieldName)
+}
+
+// githubSlug returns the anchor that GitHub generates for a Markdown heading
+func githubSlug(heading string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			builder.WriteRune(r)
+		case r == ' ':
+			builder.WriteRune('-')
+		}
+	}
+	return builder.String()
+}
+
+// tableOfContents writes a list of links to the combined table's heading and the named structs' headings, with
+// the first line of each struct's doc as its description
+func tableOfContents(builder *strings.Builder, opts *Options, namedStructs []Struct) {
+	if opts.CompositeHeading != "" {
+		builder.WriteString("- [" + opts.CompositeHeading + "](#" + githubSlug(opts.CompositeHeading) + ")\n")
+	}
+	for _, str := range namedStructs {
+		builder.WriteString("- [" + str.Name + "](#" + githubSlug(str.Name) + ")")
+		if firstLine, _, _ := strings.Cut(strings.TrimSpace(str.Doc), "\n"); firstLine != "" {
+			builder.WriteString(" - " + firstLine)
+		}
+		builder.WriteString("\n")
+	}
+	for _, section := range opts.Sections {
+		builder.WriteString("- [" + section.Heading + "](#" + githubSlug(section.Heading) + ")\n")
+	}
+	if opts.BoardOptionsTable {
+		builder.WriteString("- [" + BoardOptionsHeading + "](#" + githubSlug(BoardOptionsHeading) + ")\n")
+	}
+	builder.WriteString("\n")
+}
+
+// namedStructAsMarkdown writes the heading and table for a standalone struct. If collapsible is true, the table is
+// wrapped in a <details> block summarized by the struct name and doc
+func namedStructAsMarkdown(str *Struct, builder *strings.Builder, opts *Options, heading string) {
+	if opts.Collapsible {
+		builder.WriteString("<details>\n<summary><b>" + str.Name + "</b>")
+		if str.Doc != "" {
+			builder.WriteString(": " + html.EscapeString(strings.Join(strings.Fields(str.Doc), " ")))
+		}
+		builder.WriteString("</summary>\n\n")
+		structsAsMarkdownTable(builder, true, opts, *str)
+		builder.WriteString("\n</details>\n")
+		return
+	}
+	builder.WriteString(heading + " " + str.Name + "\n")
+	if str.Doc != "" {
+		builder.WriteString(str.Doc)
+	}
+	structsAsMarkdownTable(builder, true, opts, *str)
+}
+
+// expandedSliceStruct returns the parsed struct used as the element type of field if it is a slice of structs and
+// opts.ExpandSliceStructs is set
+func expandedSliceStruct(field *Field, opts *Options) (Struct, bool) {
+	if !opts.ExpandSliceStructs || !strings.HasPrefix(field.Type, "[]") {
+		return Struct{}, false
+	}
+	str, ok := opts.structs[elementTypeName(field.Type)]
+	return str, ok
+}
+
+// structsAsMarkdownTable writes a table of the non-deprecated fields of the given structs, or a note saying there
+// are none. The table gets the columns of fieldColumns that newFieldTable returns. Every row goes through
+// writeMarkdownRow with the same widths, so the header, divider, and data rows always have the same columns.
+//
+// If opts.CompositeStructDocs is set and named is false, each struct's rows are preceded by a subheading with its
+// name and doc, and the header is repeated after it.
+//
+// If opts.ExpandSliceStructs is set, the table is interrupted after each field that is a slice of a parsed struct
+// to write a sub-table of that struct's fields, and resumed (with its header repeated) before the next row
+func structsAsMarkdownTable(builder *strings.Builder, named bool, opts *Options, strs ...Struct) {
+	if opts.Admonitions && (named || !opts.CompositeStructDocs) && markdownAdmonitions(builder, strs...) &&
+		hasDocumentedFields(strs...) {
+		builder.WriteString("\n")
+	}
+	if !hasDocumentedFields(strs...) {
+		// a table with no rows isn't rendered as a table by most Markdown renderers. The blank line keeps the note
+		// from being joined to the struct's doc
+		builder.WriteString("\n" + noDocumentedFields + "\n")
+		return
+	}
+	flat := !named && opts.FlatSort == FieldSortName
+	var flatFields []structField
+	if flat {
+		flatFields = flatSortedFields(strs)
+	}
+	table := newFieldTable(opts, named, flat, strs...)
+	widths := table.widths()
+	aligns := table.alignments()
+	headers := table.headers()
+
+	needHeader := true
+	// the footnotes of the rows written since the last one was written, which go below that part of the table
+	var footnotes []string
+	writeFootnotes := func() {
+		if len(footnotes) > 0 {
+			builder.WriteString("\n" + strings.Join(footnotes, "\n") + "\n")
+			footnotes = nil
+		}
+	}
+	writeRow := func(str *Struct, field *Field) {
+		if needHeader {
+			writeMarkdownRow(builder, widths, aligns, opts.MinimalTables, headers...)
+			writeMarkdownDivider(builder, widths, aligns, opts.MinimalTables)
+			needHeader = false
+		}
+		cells := table.markdownCells(str, field)
+		info := markdownInfoText(field, opts)
+		if first, ok := footnoteSentence(field, opts); ok {
+			footnotes = append(footnotes, markdownFootnote(opts, info))
+			info = markdownCellText(first) + " " + footnoteRef(*opts.footnoteCount)
+		}
+		writeMarkdownRow(builder, widths, aligns, opts.MinimalTables, append(cells, wrapMarkdownCell(info, opts.WrapWidth))...)
+
+		if elem, ok := expandedSliceStruct(field, opts); ok {
+			writeFootnotes()
+			builder.WriteString("\n#### " + field.Name + " entries\n")
+			if elem.Doc != "" {
+				builder.WriteString(elem.Doc)
+			}
+			// only expand one level deep, to avoid recursing forever on self-referencing structs
+			elemOpts := *opts
+			elemOpts.ExpandSliceStructs = false
+			structsAsMarkdownTable(builder, true, &elemOpts, elem)
+			builder.WriteString("\n")
+			needHeader = true
+		}
+	}
+
+	if flatFields != nil {
+		for _, row := range flatFields {
+			writeRow(row.str, row.field)
+		}
+		writeFootnotes()
+		markdownGroups(builder, strs...)
+		return
+	}
+	for s, str := range strs {
+		if !named && opts.CompositeStructDocs && hasDocumentedFields(str) {
+			writeFootnotes()
+			if s > 0 {
+				builder.WriteString("\n")
+			}
+			builder.WriteString("### " + str.Name + "\n")
+			if str.Doc != "" {
+				builder.WriteString(str.Doc)
+			}
+			if opts.Admonitions && markdownAdmonitions(builder, str) {
+				builder.WriteString("\n")
+			}
+			needHeader = true
+		}
+		for f := range str.Fields {
+			if !str.Fields[f].IsDeprecated() {
+				writeRow(&strs[s], &str.Fields[f])
+			}
+		}
+	}
+	writeFootnotes()
+	markdownGroups(builder, strs...)
+}
+
+// footnoteSentence returns the first sentence of a field's doc to write in its Info cell if opts.Footnotes is set
+// and the doc is longer than that, in which case its whole Info text is moved to a footnote. A sentence ends at the
+// first ". " that isn't in parentheses or inline code, so that text like "(e.g. 10. 20)" or `a. b` doesn't end it
+func footnoteSentence(field *Field, opts *Options) (string, bool) {
+	if !opts.Footnotes {
+		return "", false
+	}
+	doc := strings.Join(strings.Fields(linkedDoc(field, opts)), " ")
+	depth := 0
+	inCode := false
+	for i := 0; i < len(doc); i++ {
+		switch doc[i] {
+		case '`':
+			inCode = !inCode
+		case '(':
+			if !inCode {
+				depth++
+			}
+		case ')':
+			if !inCode && depth > 0 {
+				depth--
+			}
+		case '.':
+			if !inCode && depth == 0 && strings.HasPrefix(doc[i+1:], " ") {
+				return doc[:i+1], true
+			}
+		}
+	}
+	return "", false
+}
+
+// markdownFootnote returns the next numbered footnote of the Markdown document, an item of an ordered list starting
+// with its anchor, which footnoteRef links to
+func markdownFootnote(opts *Options, text string) string {
+	if opts.footnoteCount == nil {
+		opts.footnoteCount = new(int)
+	}
+	*opts.footnoteCount++
+	n := strconv.Itoa(*opts.footnoteCount)
+	return n + `. <a id="footnote-` + n + `"></a>` + text
+}
+
+// footnoteRef returns the link to the numbered footnote written by markdownFootnote, as a superscript number
+func footnoteRef(n int) string {
+	return "<sup>[" + strconv.Itoa(n) + "](#footnote-" + strconv.Itoa(n) + ")</sup>"
+}
+
+// FieldGroup is a set of mutually exclusive fields of a table given the same Group annotation, as listed after the
+// table
+type FieldGroup struct {
+	Name   string
+	Fields []string
+}
+
+// exclusiveGroups returns the groups of the non-deprecated fields of strs in the order they are first used, leaving
+// out groups with only one field, which don't conflict with anything in the table
+func exclusiveGroups(strs ...Struct) []FieldGroup {
+	var groups []FieldGroup
+	for _, str := range strs {
+		for _, field := range str.Fields {
+			if field.Group == "" || field.Name == "" || field.IsDeprecated() {
+				continue
+			}
+			g := slices.IndexFunc(groups, func(group FieldGroup) bool { return group.Name == field.Group })
+			if g < 0 {
+				g = len(groups)
+				groups = append(groups, FieldGroup{Name: field.Group})
+			}
+			groups[g].Fields = append(groups[g].Fields, field.Name)
+		}
+	}
+	return slices.DeleteFunc(groups, func(group FieldGroup) bool { return len(group.Fields) < 2 })
+}
+
+// markdownGroups writes a blockquote after a table with a line for each group of mutually exclusive fields in it,
+// e.g. "**listener**: set one of `ListenAddress` or `UnixSocket`"
+func markdownGroups(builder *strings.Builder, strs ...Struct) {
+	groups := exclusiveGroups(strs...)
+	if len(groups) == 0 {
+		return
+	}
+	builder.WriteString("\n")
+	for g, group := range groups {
+		if g > 0 {
+			// a blank quoted line keeps each group on its own line when rendered
+			builder.WriteString(">\n")
+		}
+		builder.WriteString("> **" + markdownCellText(group.Name) + "**: set one of " + joinOr(group.Fields, "`") + "\n")
+	}
+}
+
+// joinOr joins values into a list like "A, B, or C", with each value wrapped in quote
+func joinOr(values []string, quote string) string {
+	quoted := make([]string, len(values))
+	for v, value := range values {
+		quoted[v] = quote + value + quote
+	}
+	if len(quoted) == 2 {
+		return quoted[0] + " or " + quoted[1]
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + ", or " + quoted[len(quoted)-1]
+}
+
+// structField is a field with the struct it is declared in
+type structField struct {
+	str   *Struct
+	field *Field
+}
+
+// flatSortedFields returns the non-deprecated fields of strs sorted by name, ignoring case. Fields with the same
+// name keep the order of their structs
+func flatSortedFields(strs []Struct) []structField {
+	var fields []structField
+	for s := range strs {
+		for f := range strs[s].Fields {
+			if field := &strs[s].Fields[f]; field.Name != "" && !field.IsDeprecated() {
+				fields = append(fields, structField{str: &strs[s], field: field})
+			}
+		}
+	}
+	slices.SortStableFunc(fields, func(a, b structField) int {
+		return strings.Compare(strings.ToLower(a.field.Name), strings.ToLower(b.field.Name))
+	})
+	return fields
+}
+
+// markdownAdmonitions writes a GitHub [!IMPORTANT] admonition listing the required fields of the given structs and
+// a [!WARNING] admonition listing their deprecated fields with the deprecation notices, if they have any, and
+// returns whether it wrote either. Each is preceded by a blank line, but it is up to the caller to write one after
+// them if needed
+func markdownAdmonitions(builder *strings.Builder, strs ...Struct) bool {
+	var required, deprecated []string
+	for _, str := range strs {
+		for _, field := range str.Fields {
+			switch {
+			case field.Name == "":
+			case field.IsDeprecated():
+				notice, _ := field.deprecationNotice()
+				deprecated = append(deprecated, "`"+field.Name+"`: "+markdownCellText(notice))
+			case field.Required:
+				required = append(required, "`"+field.Name+"`")
+			}
+		}
+	}
+	blankLine := func() {
+		if !strings.HasSuffix(builder.String(), "\n\n") {
+			builder.WriteString("\n")
+		}
+	}
+	if len(required) > 0 {
+		blankLine()
+		builder.WriteString("> [!IMPORTANT]\n> Required: " + strings.Join(required, ", ") + "\n")
+	}
+	if len(deprecated) > 0 {
+		blankLine()
+		builder.WriteString("> [!WARNING]\n> Deprecated:\n")
+		for _, line := range deprecated {
+			builder.WriteString("> - " + line + "\n")
+		}
+	}
+	return len(required) > 0 || len(deprecated) > 0
+}
+
+// RenderMarkdown renders opts.Header, the combined table of opts.CompositeStructs, opts.CompositeFooter, the
+// tables of opts.NamedStructs, opts.Sections, and the board options table (see Options.BoardOptionsTable) as
+// Markdown
+func RenderMarkdown(structs []Struct, opts Options) string {
+	compositeStructs, namedStructs, sectionStructs := markdownStructs(structs, &opts)
+
+	var builder strings.Builder
+	builder.WriteString(opts.Header)
+	if opts.TableOfContents {
+		tableOfContents(&builder, &opts, namedStructs)
+	}
+	if opts.CompositeHeading != "" {
+		builder.WriteString("## " + opts.CompositeHeading + "\n")
+	}
+
+	if opts.GroupByFile {
+		fileGroupsAsMarkdown(&builder, &opts, compositeStructs, namedStructs)
+		builder.WriteString(opts.CompositeFooter)
+		sectionsAsMarkdown(&builder, &opts, sectionStructs)
+		boardOptionsAsMarkdown(&builder, &opts, compositeStructs)
+		enumsAsMarkdown(&builder, &opts)
+		return builder.String()
+	}
+
+	if len(compositeStructs) > 0 {
+		structsAsMarkdownTable(&builder, false, &opts, compositeStructs...)
+	}
+	builder.WriteString(opts.CompositeFooter)
+
+	for s := range namedStructs {
+		blankLine(&builder)
+		namedStructAsMarkdown(&namedStructs[s], &builder, &opts, "##")
+	}
+	sectionsAsMarkdown(&builder, &opts, sectionStructs)
+	boardOptionsAsMarkdown(&builder, &opts, compositeStructs)
+	enumsAsMarkdown(&builder, &opts)
+	return builder.String()
+}
+
+// BoardOptionsHeading is the heading of the table written if Options.BoardOptionsTable is set
+const BoardOptionsHeading = "board.json options"
+
+// boardOptionsAsMarkdown writes a BoardOptionsHeading section with a table of the fields of the composite structs
+// that are board options, if opts.BoardOptionsTable is set. The fields already have anchors in the combined table,
+// so they aren't given them again
+func boardOptionsAsMarkdown(builder *strings.Builder, opts *Options, compositeStructs []Struct) {
+	if !opts.BoardOptionsTable {
+		return
+	}
+	var strs []Struct
+	for _, str := range compositeStructs {
+		boardStr := str
+		boardStr.Fields = slices.DeleteFunc(slices.Clone(str.Fields), func(field Field) bool {
+			return field.Name == "" || !str.IsBoardOption(&field, opts.BoardStructs)
+		})
+		strs = append(strs, boardStr)
+	}
+	blankLine(builder)
+	builder.WriteString("## " + BoardOptionsHeading + "\n")
+	builder.WriteString("These fields can be set in a board's board.json to override their values in gochan.json for that board.\n")
+	tableOpts := *opts
+	tableOpts.FieldAnchors = false
+	tableOpts.linkedAnchors = nil
+	tableOpts.ExpandSliceStructs = false
+	structsAsMarkdownTable(builder, true, &tableOpts, strs...)
+}
+
+// sectionsAsMarkdown writes each of opts.Sections with its heading, text, and the tables of its structs, which are in
+// sectionStructs at the same index
+func sectionsAsMarkdown(builder *strings.Builder, opts *Options, sectionStructs [][]Struct) {
+	for s, section := range opts.Sections {
+		blankLine(builder)
+		builder.WriteString("## " + section.Heading + "\n")
+		builder.WriteString(section.Text)
+		for t := range sectionStructs[s] {
+			if t > 0 || section.Text != "" {
+				blankLine(builder)
+			}
+			namedStructAsMarkdown(&sectionStructs[s][t], builder, opts, "###")
+		}
+	}
+}
+
+// blankLine ends what has been written to builder with a blank line before a heading, unless it is empty or already
+// ends with one (e.g. after opts.CompositeFooter)
+func blankLine(builder *strings.Builder) {
+	if builder.Len() > 0 && !strings.HasSuffix(builder.String(), "\n\n") {
+		builder.WriteString("\n")
+	}
+}
+
+// markdownStructs sets opts.structs and returns the composite and named structs rendered as Markdown and the
+// structs of each of opts.Sections, which are left out of the named structs, after resolving the See references of
+// their fields and of the expanded slice structs. If opts.structFiles is set, the expanded slice structs are added
+// to it with the file of the first struct that has them as a field
+func markdownStructs(structs []Struct, opts *Options) (compositeStructs, namedStructs []Struct, sectionStructs [][]Struct) {
+	opts.structs = tableStructs(structs, opts)
+	var inSection []string
+	for _, section := range opts.Sections {
+		var strs []Struct
+		for _, structName := range section.Structs {
+			if str, ok := opts.structs[structName]; ok {
+				strs = append(strs, str)
+				inSection = append(inSection, structName)
+			} else {
+				warnf(opts.Warnings, "struct %s of section %s not found, skipping it", structName, section.Heading)
+			}
+		}
+		sectionStructs = append(sectionStructs, strs)
+	}
+	for _, structName := range namedStructNames(opts.structs, opts) {
+		str, ok := opts.structs[structName]
+		if !ok {
+			warnf(opts.Warnings, "named struct %s not found, skipping it", structName)
+			continue
+		}
+		if !slices.Contains(inSection, structName) {
+			namedStructs = append(namedStructs, str)
+		}
+	}
+
+	compositeStructs = compositeStructList(opts.structs, opts)
+	rendered := slices.Concat(append([][]Struct{compositeStructs, namedStructs}, sectionStructs...)...)
+	for _, str := range rendered {
+		for _, field := range str.Fields {
+			expanded, ok := expandedSliceStruct(&field, opts)
+			if ok && !slices.ContainsFunc(rendered, func(str Struct) bool { return str.Name == expanded.Name }) {
+				rendered = append(rendered, expanded)
+				if opts.structFiles != nil {
+					// sub-tables are written in the file of the table they interrupt
+					opts.structFiles[expanded.Name] = opts.structFiles[str.Name]
+				}
+			}
+		}
+	}
+	resolveSeeLinks(opts, rendered)
+	// footnotes are numbered across the whole document, so that their anchors are unique
+	opts.footnoteCount = new(int)
+	return compositeStructs, namedStructs, sectionStructs
+}
+
+// MarkdownFile is a file written by RenderMarkdownFiles
+type MarkdownFile struct {
+	Name    string // the file name, e.g. "siteconfig.md"
+	Content string
+}
+
+// IndexFile is the name of the file that RenderMarkdownFiles writes opts.Header and the links to the other files
+// to
+const IndexFile = "index.md"
+
+// MarkdownFileName returns the name of the file that RenderMarkdownFiles writes a struct's table to, its name in
+// lower case with a .md extension, e.g. "siteconfig.md" or "geoip.country.md"
+func MarkdownFileName(structName string) string {
+	return strings.ToLower(structName) + ".md"
+}
+
+// RenderMarkdownFiles renders the same structs as RenderMarkdown as separate files for a documentation site with
+// a page per struct. Each of opts.CompositeStructs and opts.NamedStructs (including the referenced structs that
+// would be rendered after them) gets a file named by MarkdownFileName with a heading, its doc, and its table.
+// Composite structs keep the Board option column of the combined table. They are preceded by IndexFile, which has
+// opts.Header, a list of links to the other files described by the first line of each struct's doc,
+// opts.CompositeFooter, opts.Sections with links to the files of their structs, and the Constants table. See
+// references link to the file of the struct they refer to
+func RenderMarkdownFiles(structs []Struct, opts Options) []MarkdownFile {
+	opts.structFiles = make(map[string]string)
+	for _, structName := range slices.Concat(opts.CompositeStructs, namedStructNames(tableStructs(structs, &opts), &opts)) {
+		opts.structFiles[structName] = MarkdownFileName(structName)
+	}
+	for _, section := range opts.Sections {
+		for _, structName := range section.Structs {
+			opts.structFiles[structName] = MarkdownFileName(structName)
+		}
+	}
+	compositeStructs, namedStructs, sectionStructs := markdownStructs(structs, &opts)
+
+	var index strings.Builder
+	index.WriteString(opts.Header)
+	writeFileLinks(&index, slices.Concat(compositeStructs, namedStructs))
+	index.WriteString(opts.CompositeFooter)
+	for s, section := range opts.Sections {
+		if s > 0 {
+			index.WriteString("\n")
+		}
+		index.WriteString("## " + section.Heading + "\n")
+		index.WriteString(section.Text)
+		if section.Text != "" && len(sectionStructs[s]) > 0 {
+			index.WriteString("\n")
+		}
+		writeFileLinks(&index, sectionStructs[s])
+	}
+	enumsAsMarkdown(&index, &opts)
+
+	files := []MarkdownFile{{Name: IndexFile, Content: index.String()}}
+	for _, str := range slices.Concat(append([][]Struct{compositeStructs, namedStructs}, sectionStructs...)...) {
+		var builder strings.Builder
+		if slices.Contains(opts.CompositeStructs, str.Name) {
+			builder.WriteString("# " + str.Name + "\n")
+			if str.Doc != "" {
+				builder.WriteString(str.Doc)
+			}
+			// the struct already has its own heading
+			compositeOpts := opts
+			compositeOpts.CompositeStructDocs = false
+			compositeOpts.FlatSort = FieldSortNone
+			structsAsMarkdownTable(&builder, false, &compositeOpts, str)
+		} else {
+			namedStructAsMarkdown(&str, &builder, &opts, "#")
+		}
+		files = append(files, MarkdownFile{Name: MarkdownFileName(str.Name), Content: builder.String()})
+	}
+	return files
+}
+
+// writeFileLinks writes a list of links to the files of the given structs written by RenderMarkdownFiles,
+// described by the first line of each struct's doc
+func writeFileLinks(builder *strings.Builder, strs []Struct) {
+	for _, str := range strs {
+		builder.WriteString("- [" + str.Name + "](" + MarkdownFileName(str.Name) + ")")
+		if firstLine, _, _ := strings.Cut(strings.TrimSpace(str.Doc), "\n"); firstLine != "" {
+			builder.WriteString(" - " + firstLine)
+		}
+		builder.WriteString("\n")
+	}
+}
+
+// enumsAsMarkdown writes a Constants heading and a table with a row for each value of opts.Enums, if there are any
+func enumsAsMarkdown(builder *strings.Builder, opts *Options) {
+	if len(opts.Enums) == 0 {
+		return
+	}
+	widths := []int{5, 6, 14}
+	for _, enum := range opts.Enums {
+		widths[0] = max(widths[0], utf8.RuneCountInString(enum.Type)+1)
+		for _, val := range enum.Values {
+			widths[1] = max(widths[1], utf8.RuneCountInString(markdownCellText(val.Value))+1)
+		}
+	}
+	var aligns []columnAlign
+	if opts.AlignColumns {
+		// values are mostly numbers
+		aligns = []columnAlign{alignLeft, alignRight, alignLeft}
+	}
+	builder.WriteString("\n## Constants\n")
+	writeMarkdownRow(builder, widths, aligns, opts.MinimalTables, "Type", "Value", "Info")
+	writeMarkdownDivider(builder, widths, aligns, opts.MinimalTables)
+	for _, enum := range opts.Enums {
+		for _, val := range enum.Values {
+			info := val.Doc
+			if info == "" {
+				info = val.Name
+			}
+			writeMarkdownRow(builder, widths, aligns, opts.MinimalTables, enum.Type, markdownCellText(val.Value),
+				wrapMarkdownCell(markdownCellText(info), opts.WrapWidth))
+		}
+	}
+}
+
+// fileGroupsAsMarkdown writes a heading for each file that the given structs are declared in, sorted by path,
+// followed by a table for each struct declared in it. Composite structs keep the Board option column of the
+// combined table
+func fileGroupsAsMarkdown(builder *strings.Builder, opts *Options, compositeStructs, namedStructs []Struct) {
+	var files []string
+	for _, str := range slices.Concat(compositeStructs, namedStructs) {
+		if !slices.Contains(files, str.File) {
+			files = append(files, str.File)
+		}
+	}
+	slices.Sort(files)
+
+	for f, file := range files {
+		if f > 0 {
+			builder.WriteString("\n")
+		}
+		builder.WriteString("## " + file + "\n")
+		for _, str := range compositeStructs {
+			if str.File != file {
+				continue
+			}
+			builder.WriteString("\n### " + str.Name + "\n")
+			if str.Doc != "" {
+				builder.WriteString(str.Doc)
+			}
+			// each composite struct already has its own heading
+			compositeOpts := *opts
+			compositeOpts.CompositeStructDocs = false
+			compositeOpts.FlatSort = FieldSortNone
+			structsAsMarkdownTable(builder, false, &compositeOpts, str)
+		}
+		for s := range namedStructs {
+			if namedStructs[s].File != file {
+				continue
+			}
+			builder.WriteString("\n")
+			namedStructAsMarkdown(&namedStructs[s], builder, opts, "###")
+		}
+	}
+}
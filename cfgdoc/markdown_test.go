@@ -0,0 +1,44 @@
+package cfgdoc
+
+import (
+	"strings"
+	"testing"
+)
+
+// tableColumns returns the number of columns of a Markdown table row, not counting escaped pipes
+func tableColumns(row string) int {
+	return strings.Count(strings.ReplaceAll(row, `\|`, ""), "|") + 1
+}
+
+func TestMarkdownTablePipes(t *testing.T) {
+	structs := []Struct{{
+		Name: "Example",
+		Fields: []Field{
+			{Name: "Mode", Type: "string", Default: "a|b", Doc: "Mode is either a | b, or\nc   |   d"},
+			{Name: "Other", Type: "int", Doc: "Other has no pipes"},
+		},
+	}}
+	output := RenderMarkdown(structs, Options{NamedStructs: []string{"Example"}})
+
+	var rows []string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.Contains(line, "|") {
+			rows = append(rows, line)
+		}
+	}
+	if len(rows) != 4 {
+		t.Fatalf("expected a header, a divider, and 2 rows, got %d table lines in:\n%s", len(rows), output)
+	}
+	columns := tableColumns(rows[0])
+	for _, row := range rows[1:] {
+		if got := tableColumns(row); got != columns {
+			t.Errorf("row %q has %d columns, expected %d", row, got, columns)
+		}
+	}
+	if !strings.Contains(rows[2], `a \| b, or c \| d`) {
+		t.Errorf("expected the doc's pipes to be escaped and its whitespace collapsed, got %q", rows[2])
+	}
+	if !strings.Contains(rows[2], `a\|b`) {
+		t.Errorf("expected the default's pipe to be escaped, got %q", rows[2])
+	}
+}
@@ -0,0 +1,129 @@
+package cfgdoc
+
+import (
+	"slices"
+	"strings"
+)
+
+// RenderOpenAPI renders an OpenAPI 3 components.schemas block in YAML, with an object schema for each of
+// opts.CompositeStructs and opts.NamedStructs and any parsed structs they reference. Fields whose type is a parsed
+// struct reference its schema, and embedded structs are combined with allOf. Unlike the tables, deprecated fields
+// are included and marked as deprecated
+func RenderOpenAPI(structs []Struct, opts Options) string {
+	structMap := structsByName(structs)
+	names := slices.Concat(opts.CompositeStructs, namedStructNames(structMap, &opts))
+	var builder strings.Builder
+	builder.WriteString("components:\n  schemas:\n")
+	for n := 0; n < len(names); n++ {
+		str, ok := structMap[names[n]]
+		if !ok {
+			continue
+		}
+		builder.WriteString("    " + yamlString(str.Name) + ":\n")
+		indent := "      "
+		var embedded []string
+		for _, name := range str.Embedded {
+			if _, ok := structMap[name]; ok {
+				embedded = append(embedded, name)
+				if !slices.Contains(names, name) {
+					names = append(names, name)
+				}
+			}
+		}
+		for _, field := range str.Fields {
+			for _, ref := range fieldSchemaType(&field, structMap, opts.DurationFormat).refs() {
+				if !slices.Contains(names, ref) {
+					names = append(names, ref)
+				}
+			}
+		}
+		if doc := strings.Join(strings.Fields(str.Doc), " "); doc != "" {
+			builder.WriteString(indent + "description: " + yamlString(doc) + "\n")
+		}
+		if len(embedded) > 0 {
+			builder.WriteString(indent + "allOf:\n")
+			for _, name := range embedded {
+				builder.WriteString(indent + "  - $ref: " + schemaRef(name) + "\n")
+			}
+			builder.WriteString(indent + "  - type: object\n")
+			indent += "    "
+		} else {
+			builder.WriteString(indent + "type: object\n")
+		}
+		openAPIProperties(&builder, &str, structMap, &opts, indent)
+	}
+	return builder.String()
+}
+
+// openAPIProperties writes the properties and required fields of str's object schema at the given indent
+func openAPIProperties(builder *strings.Builder, str *Struct, structMap map[string]Struct, opts *Options, indent string) {
+	var required []string
+	wroteProperties := false
+	for _, field := range str.Fields {
+		if field.Name == "" || field.Unexported {
+			continue
+		}
+		if !wroteProperties {
+			builder.WriteString(indent + "properties:\n")
+			wroteProperties = true
+		}
+		builder.WriteString(indent + "  " + yamlString(field.Key()) + ":\n")
+		propIndent := indent + "    "
+		writeOpenAPISchema(builder, fieldSchemaType(&field, structMap, opts.DurationFormat), propIndent)
+		if info := infoText(&field, opts); info != "" {
+			builder.WriteString(propIndent + "description: " + yamlString(info) + "\n")
+		}
+		if field.Default != "" && !field.Sensitive {
+			builder.WriteString(propIndent + "default: " + yamlValue(jsonValue(&field, structMap, opts.DurationFormat)) + "\n")
+		}
+		if field.IsDeprecated() {
+			builder.WriteString(propIndent + "deprecated: true\n")
+		}
+		if field.Required {
+			required = append(required, field.Key())
+		}
+	}
+	if len(required) > 0 {
+		builder.WriteString(indent + "required:\n")
+		for _, key := range required {
+			builder.WriteString(indent + "  - " + yamlString(key) + "\n")
+		}
+	}
+}
+
+// writeOpenAPISchema writes the keys of a type's schema at the given indent
+func writeOpenAPISchema(builder *strings.Builder, schema *schemaType, indent string) {
+	switch {
+	case schema.Ref != "":
+		builder.WriteString(indent + "$ref: " + schemaRef(schema.Ref) + "\n")
+		return
+	case schema.Type == "":
+		// any value, which has an empty schema
+		return
+	}
+	builder.WriteString(indent + "type: " + schema.Type + "\n")
+	if schema.Format != "" {
+		builder.WriteString(indent + "format: " + schema.Format + "\n")
+	}
+	if schema.Items != nil {
+		builder.WriteString(indent + "items:" + emptySchema(schema.Items) + "\n")
+		writeOpenAPISchema(builder, schema.Items, indent+"  ")
+	}
+	if schema.AdditionalProperties != nil {
+		builder.WriteString(indent + "additionalProperties:" + emptySchema(schema.AdditionalProperties) + "\n")
+		writeOpenAPISchema(builder, schema.AdditionalProperties, indent+"  ")
+	}
+}
+
+// emptySchema returns " {}" if schema has no keys to write under it, so that its parent key isn't left null
+func emptySchema(schema *schemaType) string {
+	if schema.Ref == "" && schema.Type == "" {
+		return " {}"
+	}
+	return ""
+}
+
+// schemaRef returns the quoted reference to the schema of the named struct
+func schemaRef(name string) string {
+	return "'#/components/schemas/" + name + "'"
+}
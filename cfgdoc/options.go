@@ -0,0 +1,367 @@
+package cfgdoc
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Options control which structs are rendered and how
+type Options struct {
+	// Header is written at the start of Markdown output
+	Header string
+
+	// CompositeStructs are rendered together as one combined table, with a Board option column if only some of their
+	// fields are board options
+	CompositeStructs []string
+
+	// CompositeFooter is written after the combined table in Markdown output
+	CompositeFooter string
+
+	// Sections are written after the named structs in Markdown output, to group related content under its own
+	// heading
+	Sections []Section
+
+	// BoardOptionsTable writes a BoardOptionsHeading section with a table of only the fields of CompositeStructs
+	// that are board options after the named structs and Sections in Markdown output, as a reference of what can be
+	// set in board.json. It isn't written by RenderMarkdownFiles
+	BoardOptionsTable bool
+
+	// NamedStructs are rendered after the combined table, each as its own table under a heading. Structs used as
+	// field types (directly, through pointers, or as slice elements or map values) by fields of the rendered structs
+	// are rendered after them the same way
+	NamedStructs []string
+
+	// NoReferencedStructs disables rendering structs used by fields of the rendered structs that aren't in
+	// NamedStructs
+	NoReferencedStructs bool
+
+	// BoardStructs lists the structs whose fields can be overridden in board.json, unless a struct's doc comment
+	// has a BoardOption annotation
+	BoardStructs []string
+
+	// CompositeHeading, if set, is written as a heading before the combined table in Markdown output
+	CompositeHeading string
+
+	// TableOfContents writes a list linking to the combined table and each named struct's heading after the header
+	// in Markdown output. CompositeHeading should be set so that the combined table has a heading to link to
+	TableOfContents bool
+
+	// ResolveAliases shows the type that declared non-struct types and aliases resolve to next to their name in the
+	// Type column
+	ResolveAliases bool
+
+	// WithSource appends the position of each field's declaration to its Info column
+	WithSource bool
+
+	// ExpandSliceStructs writes a sub-table of the element struct's fields after each field that is a slice of a
+	// parsed struct in Markdown output, instead of rendering the element struct as a named struct
+	ExpandSliceStructs bool
+
+	// MinimalTables writes Markdown tables without padding the cells to the width of their columns, so that a change
+	// to one cell doesn't change every row of the table
+	MinimalTables bool
+
+	// AlignColumns gives the columns of Markdown tables explicit alignments with colons in their dividers, with the
+	// Board option and Required columns centered, the Value column of the Constants table right-aligned, and the
+	// others left-aligned. Padded cells are padded to match their column's alignment
+	AlignColumns bool
+
+	// Collapsible wraps each named struct table in a <details> block in Markdown output
+	Collapsible bool
+
+	// Standalone wraps HTML output in a complete HTML document
+	Standalone bool
+
+	// FlatSort, if set, sorts the fields of the combined table into one flat list across the composite structs in
+	// Markdown and HTML output, with a Struct column giving the struct each field is declared in, instead of grouping
+	// them by struct in source order. It overrides CompositeStructDocs
+	FlatSort FieldSort
+
+	// CompositeStructDocs splits the combined table by the struct each field is declared in, with the struct's name
+	// and doc before its fields
+	CompositeStructDocs bool
+
+	// GroupByFile writes a heading for each file that the rendered structs are declared in, followed by a table
+	// for each of those structs, in Markdown output instead of the combined table
+	GroupByFile bool
+
+	// WrapWidth, if greater than 0, soft-wraps the Info column of Markdown tables into lines of at most WrapWidth
+	// characters separated by <br>
+	WrapWidth int
+
+	// Admonitions writes GitHub admonitions before each Markdown table, summarizing the required and deprecated
+	// fields of its structs
+	Admonitions bool
+
+	// FieldAnchors gives each field's row an id (see FieldAnchor) in HTML output, and writes an anchor with that id
+	// before each field's name in Markdown output, so that fields can be linked to
+	FieldAnchors bool
+
+	// Footnotes keeps the Info cells of Markdown tables short by writing only the first sentence of a field's doc in
+	// it, followed by a link to a numbered footnote below the table with the whole text, for fields whose doc has more
+	// than one sentence
+	Footnotes bool
+
+	// AutoLink links the backtick-quoted names of other fields in field docs (e.g. `SiteName` or
+	// `SiteConfig.SiteName`) to them in Markdown output, resolving them like See references. Only exact-case names of
+	// rendered fields are linked, with no warning for other inline code
+	AutoLink bool
+
+	// MaxDefaultWidth, if greater than 0, truncates defaults longer than MaxDefaultWidth characters in the Default
+	// column of the Markdown, HTML, and terminal tables, ending them with an ellipsis. The example configs and
+	// schemas always use the full value
+	MaxDefaultWidth int
+
+	// EnvPrefix is prepended to the environment variable names in dotenv output
+	EnvPrefix string
+
+	// DurationFormat is how time.Duration values are written in the example configs and schemas, which should be
+	// the way gochan marshals them. Defaults are shown as they are written in the tables
+	DurationFormat DurationFormat
+
+	// Enums are rendered in a Constants table after the named structs in Markdown and HTML output
+	Enums []Enum
+
+	// Warnings is where warnings about the rendered structs (e.g. a composite struct that wasn't parsed) are
+	// written, or stderr if it is nil
+	Warnings io.Writer
+
+	structs map[string]Struct // the structs being rendered, keyed by name
+
+	// seeLinks are the Markdown links of the resolved See references of the rendered fields, keyed by seeKey
+	seeLinks map[string]string
+
+	// docLinks are the Markdown links of the field names quoted in the docs of the rendered fields with AutoLink,
+	// keyed by seeKey
+	docLinks map[string]string
+
+	// footnoteCount is the number of the last footnote written in the Markdown document with Footnotes, shared by
+	// the copies of the Options made for sub-tables
+	footnoteCount *int
+
+	// linkedAnchors are the field anchors that See references link to, which are written even if FieldAnchors isn't
+	// set
+	linkedAnchors map[string]bool
+
+	// structFiles are the files that RenderMarkdownFiles writes the tables of the rendered structs to, keyed by
+	// struct name, so that See references link to them. It is nil when rendering a single file
+	structFiles map[string]string
+}
+
+// Section is a part of Markdown output with a heading, Markdown text written after it, and the tables of its
+// structs under their own subheadings. Its structs are written in the section instead of with the named structs,
+// and are rendered in other formats only if they are named or referenced like other structs
+type Section struct {
+	Heading string
+	Text    string
+	Structs []string
+}
+
+// DurationFormat is the JSON representation of time.Duration values
+type DurationFormat string
+
+const (
+	// DurationNanoseconds writes durations as integer numbers of nanoseconds, like encoding/json does. It is used if
+	// Options.DurationFormat isn't set
+	DurationNanoseconds DurationFormat = "ns"
+
+	// DurationString writes durations as strings parsed by time.ParseDuration, e.g. "1h30m", as marshaled by a
+	// custom duration type
+	DurationString DurationFormat = "string"
+)
+
+// FieldSort is the order of the fields of the combined table set by Options.FlatSort
+type FieldSort string
+
+const (
+	// FieldSortNone keeps the fields grouped by the struct they are declared in, in source order
+	FieldSortNone FieldSort = ""
+
+	// FieldSortName sorts the fields by name, ignoring case
+	FieldSortName FieldSort = "name"
+)
+
+// tableStructs returns the given structs keyed by name like structsByName, except that each struct that isn't one of
+// opts.CompositeStructs gets the fields of the structs it embeds (and theirs) after its own, since they are set in
+// the same object and belong in its table. A promoted field is left out if a field of the same name is declared
+// closer to the struct, like in Go. The structs that composite structs embed are in opts.CompositeStructs
+// themselves instead, see ExpandComposites
+func tableStructs(structs []Struct, opts *Options) map[string]Struct {
+	structMap := structsByName(structs)
+	tables := make(map[string]Struct, len(structMap))
+	for name, str := range structMap {
+		if len(str.Embedded) > 0 && !slices.Contains(opts.CompositeStructs, name) {
+			fields := slices.Clone(str.Fields)
+			for _, embedded := range str.Embedded {
+				nested, ok := structMap[embedded]
+				if !ok || embedded == name {
+					continue
+				}
+				for _, field := range promotedFields(&nested, structMap, []string{name, embedded}) {
+					if !slices.ContainsFunc(fields, func(declared Field) bool { return declared.Name == field.Name }) {
+						fields = append(fields, field)
+					}
+				}
+			}
+			str.Fields = fields
+		}
+		tables[name] = str
+	}
+	return tables
+}
+
+// namedStructNames returns opts.NamedStructs followed by any parsed structs referenced as field types, slice
+// elements, or map values by fields of the composite, named, or other referenced structs that aren't already
+// rendered. The referenced structs are sorted by name, so that their order doesn't depend on which field happens to
+// reference them first
+func namedStructNames(structMap map[string]Struct, opts *Options) []string {
+	rendered := slices.Concat(opts.CompositeStructs, opts.NamedStructs)
+	names := slices.Clone(opts.NamedStructs)
+	if opts.NoReferencedStructs {
+		return names
+	}
+	var referenced []string
+	for s := 0; s < len(rendered); s++ {
+		str := structMap[rendered[s]]
+		// the fields of embedded structs are part of the embedding struct in the config, and can reference structs too
+		for _, field := range promotedFields(&str, structMap, []string{str.Name}) {
+			elem := referencedTypeName(field.Type)
+			if opts.ExpandSliceStructs && strings.HasPrefix(field.Type, "[]") {
+				// written as a sub-table after the field instead
+				continue
+			}
+			if _, ok := structMap[elem]; !ok || slices.Contains(rendered, elem) {
+				continue
+			}
+			rendered = append(rendered, elem)
+			referenced = append(referenced, elem)
+		}
+	}
+	slices.Sort(referenced)
+	return append(names, referenced...)
+}
+
+// warnf writes a warning line to w, or to stderr if w is nil
+func warnf(w io.Writer, format string, args ...any) {
+	if w == nil {
+		w = os.Stderr
+	}
+	fmt.Fprintf(w, "Warning: "+format+"\n", args...)
+}
+
+// defaultCell returns the field's DefaultText as shown in the Default column of a table, truncated with an ellipsis
+// if opts.MaxDefaultWidth is set and it is longer
+func defaultCell(field *Field, opts *Options) string {
+	text := field.DefaultText()
+	if opts.MaxDefaultWidth <= 0 || utf8.RuneCountInString(text) <= opts.MaxDefaultWidth {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimRight(string(runes[:max(opts.MaxDefaultWidth-1, 0)]), " ") + "…"
+}
+
+// infoText returns the text of a field's Info column
+func infoText(field *Field, opts *Options) string {
+	return strings.TrimSpace(strings.Join(strings.Fields(linkedDoc(field, opts)), " ") + infoAnnotations(field, opts))
+}
+
+// infoAnnotations returns the text appended to a field's doc in its Info column: its annotations, with its See
+// references linked if they were resolved by resolveSeeLinks, and the position of its declaration if
+// opts.WithSource is set
+func infoAnnotations(field *Field, opts *Options) string {
+	see := field.See
+	if opts.seeLinks != nil {
+		see = make([]string, len(field.See))
+		for r, ref := range field.See {
+			see[r] = ref
+			if link, ok := opts.seeLinks[seeKey(field, ref)]; ok {
+				see[r] = link
+			}
+		}
+	}
+	info := field.annotationText(see)
+	if opts.WithSource {
+		info += " (source: " + field.Source() + ")"
+	}
+	return info
+}
+
+// compositeStructList returns the parsed structs named in opts.CompositeStructs, in order. Names that weren't
+// parsed are skipped with a warning, rather than being rendered as empty rows
+func compositeStructList(structMap map[string]Struct, opts *Options) []Struct {
+	compositeStructs := make([]Struct, 0, len(opts.CompositeStructs))
+	for _, structName := range opts.CompositeStructs {
+		str, ok := structMap[structName]
+		if !ok {
+			warnf(opts.Warnings, "composite struct %s not found, skipping it", structName)
+			continue
+		}
+		compositeStructs = append(compositeStructs, str)
+	}
+	return compositeStructs
+}
+
+// StructRole is the reason a parsed struct is or isn't rendered with the given Options, as returned by StructRoles
+type StructRole string
+
+const (
+	RoleComposite  StructRole = "composite"     // in Options.CompositeStructs
+	RoleNamed      StructRole = "named"         // in Options.NamedStructs
+	RoleReferenced StructRole = "auto-included" // used by a field of a rendered struct
+	RoleIgnored    StructRole = "ignored"       // not rendered
+)
+
+// StructRoles returns the role of each of the given structs in the output rendered with opts, keyed by struct name
+func StructRoles(structs []Struct, opts Options) map[string]StructRole {
+	structMap := structsByName(structs)
+	roles := make(map[string]StructRole, len(structs))
+	for _, str := range structs {
+		roles[str.Name] = RoleIgnored
+	}
+	for _, structName := range namedStructNames(structMap, &opts) {
+		if _, ok := roles[structName]; ok {
+			roles[structName] = RoleReferenced
+		}
+	}
+	for _, structName := range opts.NamedStructs {
+		if _, ok := roles[structName]; ok {
+			roles[structName] = RoleNamed
+		}
+	}
+	for _, structName := range opts.CompositeStructs {
+		if _, ok := roles[structName]; ok {
+			roles[structName] = RoleComposite
+		}
+	}
+	return roles
+}
+
+// Summary returns a line summarizing how many fields of the structs rendered with opts are documented, e.g.
+// "documented 84/91 fields across 9 structs (7 deprecated, 7 undocumented)". Deprecated fields count as
+// documented, and embedded structs aren't counted as fields
+func Summary(structs []Struct, opts Options) string {
+	roles := StructRoles(structs, opts)
+	var numStructs, documented, deprecated, undocumented int
+	for _, str := range structs {
+		if roles[str.Name] == RoleIgnored {
+			continue
+		}
+		numStructs++
+		undocumented += len(str.Undocumented)
+		for _, field := range str.Fields {
+			if field.Name == "" {
+				continue
+			}
+			documented++
+			if field.IsDeprecated() {
+				deprecated++
+			}
+		}
+	}
+	return fmt.Sprintf("documented %d/%d fields across %d structs (%d deprecated, %d undocumented)",
+		documented, documented+undocumented, numStructs, deprecated, undocumented)
+}
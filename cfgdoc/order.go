@@ -0,0 +1,37 @@
+package cfgdoc
+
+import (
+	"fmt"
+	"slices"
+)
+
+// OrderFields moves the fields named in order (keyed by struct name) to the start of their struct's Fields, in the
+// given order, leaving the fields that aren't named after them in source order. It returns an error if a struct or
+// field isn't found
+func OrderFields(structs []Struct, order map[string][]string) error {
+	for structName, fieldNames := range order {
+		s := slices.IndexFunc(structs, func(str Struct) bool { return str.Name == structName })
+		if s < 0 {
+			return fmt.Errorf("struct %s not found", structName)
+		}
+		fields := structs[s].Fields
+		ordered := make([]Field, 0, len(fields))
+		for n, fieldName := range fieldNames {
+			if slices.Contains(fieldNames[:n], fieldName) {
+				continue
+			}
+			f := slices.IndexFunc(fields, func(field Field) bool { return field.Name == fieldName })
+			if f < 0 {
+				return fmt.Errorf("field %s.%s not found", structName, fieldName)
+			}
+			ordered = append(ordered, fields[f])
+		}
+		for _, field := range fields {
+			if !slices.Contains(fieldNames, field.Name) {
+				ordered = append(ordered, field)
+			}
+		}
+		structs[s].Fields = ordered
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package cfgdoc
+
+import (
+	"go/doc/comment"
+	"go/token"
+	"strings"
+)
+
+// ParsePackageDoc parses the non-test Go files in dir for the package doc comment, the comment before the package
+// clause, and returns it as Markdown, with its headings (lines like "# Heading") at the given level. If more than
+// one file has a package comment (which should be avoided), they are joined in file order, like go doc does. It
+// returns an empty string if there is no package comment
+func ParsePackageDoc(dir string, headingLevel int) (string, error) {
+	paths, err := goFiles(dir, nil)
+	if err != nil {
+		return "", err
+	}
+	fset := token.NewFileSet()
+	var docs []string
+	for _, path := range paths {
+		file, err := parseFile(fset, path, path)
+		if err != nil {
+			return "", err
+		}
+		if file.Doc != nil {
+			docs = append(docs, file.Doc.Text())
+		}
+	}
+	if len(docs) == 0 {
+		return "", nil
+	}
+	var parser comment.Parser
+	printer := comment.Printer{
+		HeadingLevel: headingLevel,
+		// the {#id} heading attributes written by default aren't supported by GitHub, which generates its own ids
+		HeadingID: func(*comment.Heading) string { return "" },
+	}
+	return string(printer.Markdown(parser.Parse(strings.Join(docs, "\n")))), nil
+}
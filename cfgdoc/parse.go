@@ -0,0 +1,975 @@
+// Package cfgdoc extracts the documentation of gochan's configuration structs from their source code and renders
+// it as Markdown or HTML tables
+package cfgdoc
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// OptionalBool is a boolean annotation value that may not be set in a doc comment
+type OptionalBool int
+
+const (
+	BoolUnset OptionalBool = iota
+	BoolTrue
+	BoolFalse
+)
+
+// parseBoolAnnotation returns the value of line if it is a boolean annotation in the form "Prefix: true|false"
+// (case-insensitive prefix), or BoolUnset if it isn't
+func parseBoolAnnotation(line string, prefix string) OptionalBool {
+	if len(line) < len(prefix) || !strings.EqualFold(line[:len(prefix)], prefix) {
+		return BoolUnset
+	}
+	val, err := strconv.ParseBool(strings.TrimSpace(line[len(prefix):]))
+	if err != nil {
+		return BoolUnset
+	}
+	if val {
+		return BoolTrue
+	}
+	return BoolFalse
+}
+
+// parseStringAnnotation returns the text following prefix (case-insensitive) if line starts with it
+func parseStringAnnotation(line string, prefix string) (string, bool) {
+	if len(line) < len(prefix) || !strings.EqualFold(line[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(line[len(prefix):]), true
+}
+
+// defaultPlatforms returns the platforms of the field's PlatformDefaults, sorted
+func (f *Field) defaultPlatforms() []string {
+	platforms := make([]string, 0, len(f.PlatformDefaults))
+	for platform := range f.PlatformDefaults {
+		platforms = append(platforms, platform)
+	}
+	slices.Sort(platforms)
+	return platforms
+}
+
+// parsePlatformDefault parses a Default[platform]: annotation (in any case, like other annotations), returning the
+// platform and the default
+func parsePlatformDefault(line string) (string, string, bool) {
+	const prefix = "Default["
+	if len(line) < len(prefix) || !strings.EqualFold(line[:len(prefix)], prefix) {
+		return "", "", false
+	}
+	platform, def, ok := strings.Cut(line[len(prefix):], "]:")
+	platform = strings.TrimSpace(platform)
+	if !ok || platform == "" {
+		return "", "", false
+	}
+	return platform, strings.TrimSpace(def), true
+}
+
+// ConfigDirective is a line in a struct's doc comment marking it as a config struct, see ParseOptions.MarkedOnly.
+// Like other directives, it isn't part of the doc
+const ConfigDirective = "//cfgdoc:config"
+
+// hasConfigDirective returns true if doc has the ConfigDirective line
+func hasConfigDirective(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	return slices.ContainsFunc(doc.List, func(c *ast.Comment) bool {
+		return strings.TrimSpace(c.Text) == ConfigDirective
+	})
+}
+
+// extractBoardOption removes any "BoardOption: true|false" line from a struct's doc comment, returning the
+// remaining doc and the annotation value
+func extractBoardOption(doc string) (string, OptionalBool) {
+	boardOption := BoolUnset
+	var remaining strings.Builder
+	for _, line := range strings.SplitAfter(doc, "\n") {
+		if val := parseBoolAnnotation(strings.TrimSpace(line), "BoardOption:"); val != BoolUnset {
+			boardOption = val
+			continue
+		}
+		remaining.WriteString(line)
+	}
+	return remaining.String(), boardOption
+}
+
+// Struct is a parsed struct declaration and its documented fields
+type Struct struct {
+	Name        string
+	Doc         string
+	Fields      []Field
+	BoardOption OptionalBool
+
+	// File is the file the struct is declared in
+	File string
+
+	// Undocumented are the exported fields without a doc comment, which aren't in Fields. Only their names, types,
+	// JSON names, and positions are set, which is enough for ValidateConfig to know that gochan still reads them
+	Undocumented []Field
+
+	// Embedded are the types of the struct's embedded fields, whether they are documented or not. Their fields are
+	// at the same level as the struct's own fields in JSON
+	Embedded []string
+
+	// Marked is set if the struct's doc comment has the ConfigDirective line
+	Marked bool
+
+	// Package is the name of the package the struct is declared in, which QualifyStructs prefixes its name with
+	Package string
+}
+
+// IsBoardConfig returns true if the struct's fields can be overridden in board.json, as set by a BoardOption
+// annotation in the struct's doc comment or by its presence in boardStructs
+func (s *Struct) IsBoardConfig(boardStructs []string) bool {
+	switch s.BoardOption {
+	case BoolTrue:
+		return true
+	case BoolFalse:
+		return false
+	}
+	return slices.Contains(boardStructs, s.Name)
+}
+
+// IsBoardOption returns true if the given field of s can be overridden in board.json. A BoardOption annotation
+// in the field's doc comment takes precedence over the struct-level setting
+func (s *Struct) IsBoardOption(field *Field, boardStructs []string) bool {
+	switch field.BoardOption {
+	case BoolTrue:
+		return true
+	case BoolFalse:
+		return false
+	}
+	return s.IsBoardConfig(boardStructs)
+}
+
+// Field is a documented struct field. Composite is set to the embedded type's name if the field is an embedded
+// struct
+type Field struct {
+	Composite   string
+	Name        string
+	Type        string
+	Default     string
+	Doc         string
+	BoardOption OptionalBool
+	Example     string
+	Units       string
+	Since       string
+	Required    bool
+	Optional    bool
+	Accepts     string
+
+	// PlatformDefaults are the defaults given by Default[platform]: annotations, keyed by platform (e.g. "linux" or
+	// "windows"), for fields whose default differs by OS or build. Default is the default on other platforms
+	PlatformDefaults map[string]string
+
+	// Keys describes what the keys of a map field are (e.g. "board directory"), given by a Keys annotation
+	Keys string
+
+	// Values are the values the field can be set to, if they are limited to a known list
+	Values []string
+
+	// Group is the name given by a Group annotation to a set of mutually exclusive fields, of which only one should
+	// be set
+	Group string
+
+	// Platforms are the OSes or build configurations the field applies to, given by a Platform annotation (e.g.
+	// "Platform: linux, bsd"). A field without one applies to all platforms
+	Platforms []string
+
+	// See are the fields (Field or Struct.Field) or structs referenced by the field's See annotations, which are
+	// linked to in Markdown output
+	See []string
+
+	// JSONName is the name given to the field by its json struct tag, if it has one
+	JSONName string
+
+	// Sensitive is set by a Sensitive annotation on fields holding secrets like passwords, which are noted in the
+	// Info column and left empty in example configs and schemas, so that a default given in the doc isn't published
+	// as the value to use
+	Sensitive bool
+
+	// OmitEmpty is set if the field's json struct tag has the omitempty or omitzero option, meaning that it can be
+	// left out of the JSON. Unless the field has a Required or Optional annotation, it is also made Optional
+	OmitEmpty bool
+
+	// File and Line are the position of the field's declaration
+	File string
+	Line int
+
+	// Underlying is the type that Type resolves to if Type is a declared non-struct type or alias, e.g. "int" for a
+	// field of type BoardID if the package declares "type BoardID int"
+	Underlying string
+
+	// CodeDefault is the value assigned to the field by the defaults function or variable, if set by SetDefaults
+	CodeDefault string
+
+	// Unexported is set if the field isn't exported, which is only parsed with ParseOptions.IncludeUnexported
+	Unexported bool
+
+	// DefaultConstant is the name of the constant that the Default annotation referred to, if it was replaced with
+	// the constant's value by ResolveConstants
+	DefaultConstant string
+
+	// InferredDefault is the zero value of the field's type (false, 0, or "") shown as its default if it has no other
+	// one, set by InferDefaults
+	InferredDefault string
+}
+
+// IsDeprecated returns true if a line of the field's doc starts with "Deprecated:", following the Go convention, so
+// that the word can still be used in prose
+func (f *Field) IsDeprecated() bool {
+	_, ok := f.deprecationNotice()
+	return ok
+}
+
+// deprecationNotice returns the field's doc after the "Deprecated:" at the start of one of its lines, and whether it
+// has one
+func (f *Field) deprecationNotice() (string, bool) {
+	for offset := 0; offset < len(f.Doc); {
+		line, _, _ := strings.Cut(f.Doc[offset:], "\n")
+		if strings.HasPrefix(line, "Deprecated:") {
+			return f.Doc[offset+len("Deprecated:"):], true
+		}
+		offset += len(line) + 1
+	}
+	return "", false
+}
+
+// DefaultText returns the field's default as shown in tables, followed by the name of the constant it was resolved
+// from in parentheses if it was, e.g. "8080 (DefaultPort)", and by its platform defaults sorted by platform, e.g.
+// "/var/log/gochan (windows: C:\gochan\log)". A field with only platform defaults shows them on their own, and a
+// field with no default shows its InferredDefault
+func (f *Field) DefaultText() string {
+	if f.Default == "" && len(f.PlatformDefaults) == 0 {
+		return f.InferredDefault
+	}
+	text := f.Default
+	if f.DefaultConstant != "" {
+		text += " (" + f.DefaultConstant + ")"
+	}
+	if len(f.PlatformDefaults) == 0 {
+		return text
+	}
+	platforms := f.defaultPlatforms()
+	for p, platform := range platforms {
+		platforms[p] = platform + ": " + f.PlatformDefaults[platform]
+	}
+	if text == "" {
+		return strings.Join(platforms, ", ")
+	}
+	return text + " (" + strings.Join(platforms, ", ") + ")"
+}
+
+// PlatformText returns the platforms the field applies to as shown in tables, e.g. "linux, bsd", or an empty string
+// if it applies to all of them
+func (f *Field) PlatformText() string {
+	return strings.Join(f.Platforms, ", ")
+}
+
+// Key returns the name of the field in JSON, from its json struct tag if it has one
+func (f *Field) Key() string {
+	if f.JSONName != "" && f.JSONName != "-" {
+		return f.JSONName
+	}
+	return f.Name
+}
+
+// TypeText returns the field's type, followed by the type it resolves to in parentheses if resolveAliases is true
+// and Type is a declared non-struct type or alias
+func (f *Field) TypeText(resolveAliases bool) string {
+	if resolveAliases && f.Underlying != "" {
+		return f.Type + " (" + f.Underlying + ")"
+	}
+	return f.Type
+}
+
+// Source returns the field's declaration position in the form "file:line"
+func (f *Field) Source() string {
+	return f.File + ":" + strconv.Itoa(f.Line)
+}
+
+// Info returns the field's doc with newlines collapsed, followed by whether it is unexported or sensitive, its
+// Optional, Keys, Accepts, Units, Example, and See annotations, and its Values (if set)
+func (f *Field) Info() string {
+	return strings.TrimSpace(strings.Join(strings.Fields(f.Doc), " ") + f.annotationText(f.See))
+}
+
+// annotationText returns the annotations appended to the field's doc in its Info column, e.g. " (optional)", with
+// see as the text of its See annotations
+func (f *Field) annotationText(see []string) string {
+	var info string
+	if f.Unexported {
+		info += " (unexported)"
+	}
+	if f.Sensitive {
+		info += " (sensitive)"
+	}
+	if f.Optional {
+		info += " (optional)"
+	}
+	if f.Group != "" {
+		info += " (group: " + f.Group + ")"
+	}
+	if f.Keys != "" {
+		info += " (keys: " + f.Keys + ")"
+	}
+	if f.Accepts != "" {
+		info += " (accepts: " + f.Accepts + ")"
+	}
+	if len(f.Values) > 0 {
+		info += " (values: " + strings.Join(f.Values, ", ") + ")"
+	}
+	if f.Units != "" {
+		info += " (units: " + f.Units + ")"
+	}
+	if f.Example != "" {
+		info += " (example: " + f.Example + ")"
+	}
+	if len(see) > 0 {
+		info += " (see: " + strings.Join(see, ", ") + ")"
+	}
+	return info
+}
+
+func parseFile(fset *token.FileSet, filename, filePath string) (*ast.File, error) {
+	ba, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return parser.ParseFile(fset, filename, string(ba), parser.ParseComments|parser.DeclarationErrors)
+}
+
+// typeString returns the representation of a field's type shown in the Type column. Types that config fields aren't
+// expected to have, other than channels and functions, are shown as written in the source after calling unexpected
+// (if it isn't nil) with them
+func typeString(expr ast.Expr, unexpected func(ast.Expr)) string {
+	switch tt := expr.(type) {
+	case *ast.Ident:
+		return tt.Name
+	case *ast.SelectorExpr:
+		// a type from another package, e.g. time.Duration
+		return fmt.Sprintf("%v.%v", tt.X, tt.Sel)
+	case *ast.BasicLit:
+		// the length of an array type, e.g. the 3 of [3]uint8
+		return tt.Value
+	case *ast.ArrayType:
+		if tt.Len != nil {
+			// a fixed length array, e.g. [3]uint8 or [MaxThemes]string
+			return "[" + typeString(tt.Len, unexpected) + "]" + typeString(tt.Elt, unexpected)
+		}
+		return "[]" + typeString(tt.Elt, unexpected)
+	case *ast.MapType:
+		return "map[" + typeString(tt.Key, unexpected) + "]" + typeString(tt.Value, unexpected)
+	case *ast.InterfaceType:
+		if len(tt.Methods.List) == 0 {
+			// interface{} and any are the same type, show them the same way
+			return "any"
+		}
+		return "interface"
+	case *ast.IndexExpr:
+		// an instantiated generic type, e.g. Option[int]
+		return typeString(tt.X, unexpected) + "[" + typeString(tt.Index, unexpected) + "]"
+	case *ast.IndexListExpr:
+		// an instantiated generic type with more than one type argument, e.g. Limits[string, int]
+		args := make([]string, len(tt.Indices))
+		for i, index := range tt.Indices {
+			args[i] = typeString(index, unexpected)
+		}
+		return typeString(tt.X, unexpected) + "[" + strings.Join(args, ", ") + "]"
+	case *ast.StarExpr:
+		// a nil pointer is an unset value, otherwise it is set the same way as the type it points to
+		return "*" + typeString(tt.X, unexpected)
+	case *ast.ChanType:
+		switch tt.Dir {
+		case ast.SEND:
+			return "chan<- " + typeString(tt.Value, unexpected)
+		case ast.RECV:
+			return "<-chan " + typeString(tt.Value, unexpected)
+		}
+		return "chan " + typeString(tt.Value, unexpected)
+	case *ast.FuncType:
+		// e.g. func(string) error
+		return types.ExprString(tt)
+	case *ast.StructType:
+		if len(tt.Fields.List) == 0 {
+			// e.g. the element type of a chan struct{} used for signaling
+			return "struct{}"
+		}
+	}
+	if unexpected != nil {
+		unexpected(expr)
+	}
+	return types.ExprString(expr)
+}
+
+// resolveType follows the type declarations in types (as collected by docFileStructs) from typ until it reaches a
+// type that isn't declared there, returning that type, or false if typ itself isn't a declared type
+func resolveType(types map[string]string, typ string) (string, bool) {
+	resolved, ok := types[typ]
+	if !ok {
+		return "", false
+	}
+	// the limit guards against cycles like "type A B; type B A", which wouldn't compile anyway
+	for range len(types) {
+		next, ok := types[resolved]
+		if !ok {
+			break
+		}
+		resolved = next
+	}
+	return resolved, true
+}
+
+// docFileStructs returns the structs declared at the package level of the given parsed file, keyed by name, and
+// the type expressions of non-struct type declarations and aliases (e.g. "type BoardID int" or
+// "type Duration = time.Duration"), also keyed by name. Field positions are resolved from fset
+func docFileStructs(fset *token.FileSet, file *ast.File, parseOpts *ParseOptions) (map[string]Struct, map[string]string) {
+	structMap := make(map[string]Struct)
+	types := make(map[string]string)
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			name := typeSpec.Name.Name
+			switch tt := typeSpec.Type.(type) {
+			case *ast.Ident, *ast.ArrayType, *ast.MapType, *ast.SelectorExpr:
+				types[name] = typeString(tt, nil)
+			case *ast.StructType:
+				doc := specDoc(genDecl, typeSpec.Doc)
+				st := docStruct(fset, name, commentText(doc), tt, parseOpts)
+				st.Marked = hasConfigDirective(doc)
+				st.Package = file.Name.Name
+				structMap[name] = st
+			}
+		}
+	}
+	return structMap, types
+}
+
+// specDoc returns the doc comment of a spec of genDecl given the spec's own doc, which is the GenDecl's if genDecl
+// declares only that spec. go/parser attaches the doc comment of an ungrouped declaration like "type X struct" or
+// "const X T = 1" to the GenDecl rather than the spec. A grouped declaration's doc describes the group, so it isn't
+// used for any of its specs, which have their own docs
+func specDoc(genDecl *ast.GenDecl, doc *ast.CommentGroup) *ast.CommentGroup {
+	if doc == nil && len(genDecl.Specs) == 1 {
+		return genDecl.Doc
+	}
+	return doc
+}
+
+// fieldDocText returns the doc of a struct field, which is the comment group above it, or its trailing comment on
+// the same line if it has none
+func fieldDocText(field *ast.Field) string {
+	if doc := commentText(field.Doc); doc != "" {
+		return doc
+	}
+	return commentText(field.Comment)
+}
+
+// commentText returns the text of a comment group written as // lines or /* */ blocks. The lines of a block are
+// usually indented along with the code around them, so their indentation is removed, which lets annotations in them
+// be found like in // lines
+func commentText(group *ast.CommentGroup) string {
+	text := group.Text()
+	if group == nil || !slices.ContainsFunc(group.List, func(c *ast.Comment) bool {
+		return strings.HasPrefix(c.Text, "/*")
+	}) {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for l, line := range lines {
+		lines[l] = strings.TrimLeft(line, " \t")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// docStruct returns the parsed declaration of the struct type t with the given name and doc comment
+func docStruct(fset *token.FileSet, name string, doc string, t *ast.StructType, parseOpts *ParseOptions) Struct {
+	st := Struct{Name: name, File: fset.Position(t.Pos()).Filename}
+	st.Doc, st.BoardOption = extractBoardOption(doc)
+	unexpectedType := func(expr ast.Expr) {
+		warnf(parseOpts.Warnings, "unexpected type %s in struct %s at %s, showing it as written", types.ExprString(expr),
+			name, fset.Position(expr.Pos()))
+	}
+	for _, field := range t.Fields.List {
+		var fieldT Field
+		if field.Names == nil {
+			// the fields of a struct embedded by pointer are promoted the same way as one embedded by value
+			fieldT.Composite = strings.TrimPrefix(typeString(field.Type, unexpectedType), "*")
+			st.Embedded = append(st.Embedded, fieldT.Composite)
+		}
+		if field.Tag != nil {
+			if tag, err := strconv.Unquote(field.Tag.Value); err == nil {
+				var jsonOpts string
+				fieldT.JSONName, jsonOpts, _ = strings.Cut(reflect.StructTag(tag).Get("json"), ",")
+				for _, opt := range strings.Split(jsonOpts, ",") {
+					fieldT.OmitEmpty = fieldT.OmitEmpty || opt == "omitempty" || opt == "omitzero"
+				}
+			}
+		}
+		pos := fset.Position(field.Pos())
+		fieldT.File = pos.Filename
+		fieldT.Line = pos.Line
+
+		docText := fieldDocText(field)
+		if docText == "" {
+			// field has no documentation, it is only kept for ValidateConfig, without warning about its type
+			fieldT.Type = typeString(field.Type, nil)
+			for _, name := range field.Names {
+				if name.IsExported() {
+					fieldT.Name = name.String()
+					st.Undocumented = append(st.Undocumented, fieldT)
+				}
+			}
+			continue
+		}
+
+		docLines := strings.Split(docText, "\n")
+		optionalSet := false // whether the Optional annotation was given, so that omitempty doesn't override it
+		for _, line := range docLines {
+			if def, ok := parseStringAnnotation(line, "Default:"); ok && fieldT.Default == "" {
+				fieldT.Default = def
+				continue
+			}
+			if platform, def, ok := parsePlatformDefault(line); ok {
+				if fieldT.PlatformDefaults == nil {
+					fieldT.PlatformDefaults = make(map[string]string)
+				}
+				fieldT.PlatformDefaults[platform] = def
+				continue
+			}
+			if val := parseBoolAnnotation(line, "BoardOption:"); val != BoolUnset {
+				fieldT.BoardOption = val
+				continue
+			}
+			if example, ok := parseStringAnnotation(line, "Example:"); ok {
+				fieldT.Example = example
+				continue
+			}
+			if units, ok := parseStringAnnotation(line, "Units:"); ok {
+				fieldT.Units = units
+				continue
+			}
+			if since, ok := parseStringAnnotation(line, "Since:"); ok {
+				fieldT.Since = since
+				continue
+			}
+			if strings.EqualFold(strings.TrimSpace(line), "Required") {
+				fieldT.Required = true
+				continue
+			}
+			if val := parseBoolAnnotation(line, "Required:"); val != BoolUnset {
+				fieldT.Required = val == BoolTrue
+				continue
+			}
+			if strings.EqualFold(strings.TrimSpace(line), "Sensitive") {
+				fieldT.Sensitive = true
+				continue
+			}
+			if val := parseBoolAnnotation(line, "Sensitive:"); val != BoolUnset {
+				fieldT.Sensitive = val == BoolTrue
+				continue
+			}
+			if group, ok := parseStringAnnotation(line, "Group:"); ok {
+				fieldT.Group = group
+				continue
+			}
+			if platforms, ok := parseStringAnnotation(line, "Platform:"); ok {
+				for _, platform := range strings.Split(platforms, ",") {
+					if platform = strings.TrimSpace(platform); platform != "" {
+						fieldT.Platforms = append(fieldT.Platforms, platform)
+					}
+				}
+				continue
+			}
+			if keys, ok := parseStringAnnotation(line, "Keys:"); ok {
+				fieldT.Keys = keys
+				continue
+			}
+			if accepts, ok := parseStringAnnotation(line, "Accepts:"); ok {
+				fieldT.Accepts = accepts
+				continue
+			}
+			if see, ok := parseStringAnnotation(line, "See:"); ok {
+				for _, ref := range strings.Split(see, ",") {
+					if ref = strings.TrimSpace(ref); ref != "" {
+						fieldT.See = append(fieldT.See, ref)
+					}
+				}
+				continue
+			}
+			if strings.EqualFold(strings.TrimSpace(line), "Optional") {
+				fieldT.Optional = true
+				optionalSet = true
+				continue
+			}
+			if val := parseBoolAnnotation(line, "Optional:"); val != BoolUnset {
+				fieldT.Optional = val == BoolTrue
+				optionalSet = true
+				continue
+			}
+			fieldT.Doc += line + "\n"
+		}
+
+		fieldT.Type = typeString(field.Type, unexpectedType)
+		if fieldT.OmitEmpty && !optionalSet && !fieldT.Required {
+			fieldT.Optional = true
+		}
+		if field.Names == nil {
+			st.Fields = append(st.Fields, fieldT)
+		}
+		// grouped declarations like "A, B int" share the same type and doc
+		for _, name := range field.Names {
+			if !name.IsExported() && (parseOpts == nil || !parseOpts.IncludeUnexported) {
+				continue
+			}
+			fieldT.Name = name.String()
+			fieldT.Unexported = !name.IsExported()
+			st.Fields = append(st.Fields, fieldT)
+		}
+	}
+	return st
+}
+
+// ParseOptions control which files ParseWith parses
+type ParseOptions struct {
+	// BuildTags, if not nil, restricts parsing to the files whose build constraints and _GOOS/_GOARCH file name
+	// suffixes are satisfied by these tags and the target platform of go/build's default context (the current one,
+	// or $GOOS and $GOARCH if set). Otherwise every file is parsed, so that platform-specific files can declare the
+	// same structs as each other
+	BuildTags []string
+
+	// IncludeUnexported includes documented unexported fields, which can't be set in JSON, with Unexported set
+	IncludeUnexported bool
+
+	// Warnings is where warnings about the parsed structs (e.g. a struct declared in more than one file) are
+	// written, or stderr if it is nil
+	Warnings io.Writer
+
+	// Verbose, if set, is where the path of each file is written as it is parsed
+	Verbose io.Writer
+
+	// MarkedOnly only returns the structs marked with the ConfigDirective and the parsed structs that their fields
+	// use (as their types, slice elements, or map values, or as embedded structs), and so on, so that a whole tree
+	// can be parsed for the config structs wherever they are declared. Structs are matched by name within the
+	// package of the field using them, or the package named by the qualifier of a type from another package (e.g.
+	// geoip.Country), and structs that share a name with a marked struct are ignored rather than reported as
+	// duplicates
+	MarkedOnly bool
+
+	// CacheDir, if set, is a directory where the structs parsed from each file are cached, keyed by the file's path,
+	// modification time, and size, so that files that haven't changed since the last run aren't parsed again. An
+	// entry that is missing or can't be read just means that the file is parsed, and the directory can be shared by
+	// runs at the same time
+	CacheDir string
+}
+
+// goFiles returns the paths of the non-test Go files in dir, in lexical order. If parseOpts isn't nil, files that
+// don't match its build tags are left out
+func goFiles(dir string, parseOpts *ParseOptions) ([]string, error) {
+	var buildContext *build.Context
+	if parseOpts != nil && parseOpts.BuildTags != nil {
+		ctx := build.Default
+		ctx.BuildTags = parseOpts.BuildTags
+		buildContext = &ctx
+	}
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if filepath.Ext(path) != ".go" || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		if buildContext != nil {
+			match, err := buildContext.MatchFile(filepath.Dir(path), d.Name())
+			if err != nil || !match {
+				return err
+			}
+		}
+		paths = append(paths, path)
+		return nil
+	})
+	return paths, err
+}
+
+// fileStruct is a parsed struct and the index of the file it was declared in, in the paths parsed by docStructs
+type fileStruct struct {
+	Struct
+	path int
+}
+
+// markedStructDecls returns the declarations of the structs marked with the ConfigDirective, and of the structs
+// that their fields use, and so on (see ParseOptions.MarkedOnly)
+func markedStructDecls(decls map[string][]fileStruct) map[string][]fileStruct {
+	kept := make(map[string][]fileStruct)
+	var queue []string
+	for name, nameDecls := range decls {
+		for _, decl := range nameDecls {
+			if decl.Marked {
+				kept[name] = append(kept[name], decl)
+			}
+		}
+		if len(kept[name]) > 0 {
+			queue = append(queue, name)
+		}
+	}
+	for len(queue) > 0 {
+		var used []string
+		for _, decl := range kept[queue[0]] {
+			used = append(used, decl.Embedded...)
+			for _, field := range decl.Fields {
+				used = append(used, referencedTypeName(field.Type))
+			}
+		}
+		for _, name := range used {
+			// an unqualified type is declared in the same package as the struct using it
+			if !strings.Contains(name, ".") {
+				name = kept[queue[0]][0].Package + "." + name
+			}
+			if _, ok := kept[name]; !ok && len(decls[name]) > 0 {
+				kept[name] = decls[name]
+				queue = append(queue, name)
+			}
+		}
+		queue = queue[1:]
+	}
+	return kept
+}
+
+// parseFileStructs parses the file at path and returns the results of docFileStructs for it, or the ones in cache if
+// it isn't nil and has an entry for the file as it is now, writing the warnings from when the file was parsed again
+func parseFileStructs(fset *token.FileSet, path string, parseOpts *ParseOptions, cache *parseCache) (map[string]Struct, map[string]string, error) {
+	if cache == nil {
+		file, err := parseFile(fset, path, path)
+		if err != nil {
+			return nil, nil, err
+		}
+		fileStructs, fileTypes := docFileStructs(fset, file, parseOpts)
+		return fileStructs, fileTypes, nil
+	}
+
+	warnings := parseOpts.Warnings
+	if warnings == nil {
+		warnings = os.Stderr
+	}
+	if entry, ok := cache.get(path); ok {
+		io.WriteString(warnings, entry.Warnings)
+		return entry.Structs, entry.Types, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := parseFile(fset, path, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var fileWarnings strings.Builder
+	fileOpts := *parseOpts
+	fileOpts.Warnings = &fileWarnings
+	fileStructs, fileTypes := docFileStructs(fset, file, &fileOpts)
+	io.WriteString(warnings, fileWarnings.String())
+	cache.put(path, info, &cacheEntry{Structs: fileStructs, Types: fileTypes, Warnings: fileWarnings.String()})
+	return fileStructs, fileTypes, nil
+}
+
+// docStructs parses the non-test Go files in dir (see goFiles) and returns the structs declared in them, keyed by
+// their name qualified with their package (e.g. config.SiteConfig), so that structs of the same name in different
+// packages don't collide. Files are parsed concurrently, but if a struct name is declared in more than one file of
+// the same package, the declaration from the file that comes first in lexical (walk) order is used, and a warning
+// naming both files is written to parseOpts.Warnings
+func docStructs(dir string, parseOpts *ParseOptions) (map[string]Struct, error) {
+	paths, err := goFiles(dir, parseOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	var cache *parseCache
+	if parseOpts.CacheDir != "" {
+		cache = &parseCache{dir: parseOpts.CacheDir, includeUnexported: parseOpts.IncludeUnexported}
+	}
+	decls := make(map[string][]fileStruct) // the declarations of each struct name
+	types := make(map[string]string)
+	var parseErr error
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	fset := token.NewFileSet()
+	jobs := make(chan int)
+	for range min(runtime.GOMAXPROCS(0), len(paths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range jobs {
+				fileStructs, fileTypes, err := parseFileStructs(fset, paths[p], parseOpts, cache)
+				if err != nil {
+					mu.Lock()
+					parseErr = err
+					mu.Unlock()
+					continue
+				}
+				mu.Lock()
+				maps.Copy(types, fileTypes)
+				for name, st := range fileStructs {
+					key := st.Package + "." + name
+					decls[key] = append(decls[key], fileStruct{Struct: st, path: p})
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for p := range paths {
+		if parseOpts.Verbose != nil {
+			fmt.Fprintln(parseOpts.Verbose, "Parsing", paths[p])
+		}
+		jobs <- p
+	}
+	close(jobs)
+	wg.Wait()
+
+	if parseOpts.MarkedOnly {
+		decls = markedStructDecls(decls)
+	}
+	names := make([]string, 0, len(decls))
+	for name := range decls {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	structMap := make(map[string]Struct, len(decls))
+	for _, name := range names {
+		// the declaration in the first file is used, in the order the files were found
+		slices.SortFunc(decls[name], func(a, b fileStruct) int { return a.path - b.path })
+		used := decls[name][0]
+		structMap[name] = used.Struct
+		for _, other := range decls[name][1:] {
+			warnf(parseOpts.Warnings, "struct %s is declared in both %s and %s, using the one in %s",
+				used.Name, paths[used.path], paths[other.path], paths[used.path])
+		}
+	}
+
+	for _, name := range names {
+		st := structMap[name]
+		for f := range st.Fields {
+			field := &st.Fields[f]
+			field.Underlying = underlyingType(types, field.Type)
+			if kind, ok := malformedJSONDefault(field); ok {
+				warnf(parseOpts.Warnings, "default %s of %s.%s at %s is not a valid JSON %s, using an empty one in example configs",
+					field.Default, st.Name, field.Name, field.Source(), kind)
+			}
+			if field.Keys != "" && !strings.HasPrefix(field.Type, "map[") && !strings.HasPrefix(field.Underlying, "map[") {
+				warnf(parseOpts.Warnings, "Keys annotation of %s.%s at %s is on a %s field, which isn't a map",
+					st.Name, field.Name, field.Source(), field.Type)
+			}
+		}
+		for f := range st.Undocumented {
+			st.Undocumented[f].Underlying = underlyingType(types, st.Undocumented[f].Type)
+		}
+		structMap[name] = st
+	}
+	return structMap, parseErr
+}
+
+// underlyingType returns the type that a field of type typ resolves to (see resolveType), or an empty string if
+// it isn't a declared type. A pointer to a declared type resolves to a pointer to the type it is declared as
+func underlyingType(types map[string]string, typ string) string {
+	name := derefType(typ)
+	resolved, ok := resolveType(types, name)
+	if !ok {
+		return ""
+	}
+	return typ[:len(typ)-len(name)] + resolved
+}
+
+// Parse parses the non-test Go files in dir and returns the structs declared in them, sorted by name
+func Parse(dir string) ([]Struct, error) {
+	return ParseWith(dir, ParseOptions{})
+}
+
+// ParseWith parses the non-test Go files in dir that are selected by parseOpts and returns the structs declared in
+// them, sorted by name and then by package. Structs of the same name can be declared in different packages when
+// parsing a tree with ParseOptions.MarkedOnly, and can be told apart by QualifyStructs
+func ParseWith(dir string, parseOpts ParseOptions) ([]Struct, error) {
+	structMap, err := docStructs(dir, &parseOpts)
+	if err != nil {
+		return nil, err
+	}
+	structs := make([]Struct, 0, len(structMap))
+	for _, st := range structMap {
+		structs = append(structs, st)
+	}
+	slices.SortFunc(structs, func(a, b Struct) int {
+		if a.Name == b.Name {
+			return strings.Compare(a.Package, b.Package)
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return structs, nil
+}
+
+// referencedTypeName returns the type whose documentation is relevant to a field of type typ: its element type if
+// it is a slice or map type (see elementTypeName), or typ itself otherwise, without the * of a pointer type
+func referencedTypeName(typ string) string {
+	if elem := elementTypeName(typ); elem != "" {
+		return elem
+	}
+	return derefType(typ)
+}
+
+// derefType returns the type that typ points to if it is a pointer type (e.g. "PageBanner" for "*PageBanner"), or
+// typ itself otherwise
+func derefType(typ string) string {
+	return strings.TrimLeft(typ, "*")
+}
+
+// elementTypeName returns the name of the element type of a slice or array type or the value type of a map type
+// (e.g. "PageBanner" for "[]PageBanner", "[]*PageBanner", or "map[string]PageBanner"), without the * of a pointer
+// type, or an empty string if typ is none of them
+func elementTypeName(typ string) string {
+	var elem string
+	switch {
+	case strings.HasPrefix(typ, "["):
+		if end := strings.Index(typ, "]"); end > 0 {
+			elem = typ[end+1:]
+		}
+	case strings.HasPrefix(typ, "map["):
+		if end := strings.Index(typ, "]"); end > 0 {
+			elem = typ[end+1:]
+		}
+	default:
+		return ""
+	}
+	elem = derefType(elem)
+	if inner := elementTypeName(elem); inner != "" {
+		return inner
+	}
+	return elem
+}
+
+// structsByName returns a map of the given structs keyed by name
+func structsByName(structs []Struct) map[string]Struct {
+	structMap := make(map[string]Struct, len(structs))
+	for _, st := range structs {
+		structMap[st.Name] = st
+	}
+	return structMap
+}
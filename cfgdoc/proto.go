@@ -0,0 +1,208 @@
+package cfgdoc
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// protoValueType is the well-known type used for fields that can hold any value or whose type has no proto
+// equivalent, like a slice of slices
+const protoValueType = "google.protobuf.Value"
+
+// RenderProto renders a proto3 file with a message for each of opts.CompositeStructs and opts.NamedStructs, with
+// a field for each of their exported fields and the fields of the structs they embed, which are at the same level
+// in JSON. Go types are mapped to proto scalar types the same way RenderOpenAPI maps them to OpenAPI types (e.g.
+// int to int64), slices to repeated fields, and maps to map fields with string keys, as in JSON. Fields whose type is
+// a parsed struct use its message, which is declared as a message nested in the first message using it if it isn't
+// one of the top-level messages. Fields are numbered in the order they are declared, and like in RenderOpenAPI,
+// deprecated fields are included and marked as deprecated
+func RenderProto(structs []Struct, opts Options) string {
+	structMap := structsByName(structs)
+	names := slices.Concat(opts.CompositeStructs, namedStructNames(structMap, &opts))
+	renderer := protoRenderer{structMap: structMap, opts: &opts, messages: make(map[string]string)}
+	for _, name := range names {
+		if _, ok := structMap[name]; ok {
+			renderer.messages[name] = protoMessageName(name)
+		}
+	}
+
+	var body strings.Builder
+	for _, name := range names {
+		str, ok := structMap[name]
+		if !ok {
+			continue
+		}
+		body.WriteString("\n")
+		renderer.writeMessage(&body, &str, "")
+	}
+
+	var builder strings.Builder
+	builder.WriteString("syntax = \"proto3\";\n")
+	if renderer.usesValue {
+		builder.WriteString("\nimport \"google/protobuf/struct.proto\";\n")
+	}
+	builder.WriteString(body.String())
+	return builder.String()
+}
+
+type protoRenderer struct {
+	structMap map[string]Struct
+	opts      *Options
+
+	// messages maps the names of the structs that have been given a message to its name, qualified with the names of
+	// the messages it is nested in
+	messages map[string]string
+
+	// usesValue is set if a field uses protoValueType, which needs google/protobuf/struct.proto to be imported
+	usesValue bool
+}
+
+// writeMessage writes the message of str at the given indent, preceded by its doc
+func (r *protoRenderer) writeMessage(builder *strings.Builder, str *Struct, indent string) {
+	writeProtoComment(builder, str.Doc, indent)
+	builder.WriteString(indent + "message " + protoMessageName(str.Name) + " {\n")
+
+	var fields []Field
+	declared := make(map[string]bool)
+	for _, field := range promotedFields(str, r.structMap, []string{str.Name}) {
+		// a field of an embedded struct is hidden by a field of the same name declared closer to str, like in JSON
+		if !field.Unexported && !declared[field.Name] {
+			fields = append(fields, field)
+			declared[field.Name] = true
+		}
+	}
+
+	wroteNested := false
+	for _, field := range fields {
+		for _, ref := range fieldSchemaType(&field, r.structMap, r.opts.DurationFormat).refs() {
+			if _, ok := r.messages[ref]; ok {
+				continue
+			}
+			nested := r.structMap[ref]
+			// added before writing it, so that a struct that contains itself refers to the message being written
+			r.messages[ref] = r.messages[str.Name] + "." + protoMessageName(ref)
+			if wroteNested {
+				builder.WriteString("\n")
+			}
+			r.writeMessage(builder, &nested, indent+"  ")
+			wroteNested = true
+		}
+	}
+
+	for f, field := range fields {
+		info := infoText(&field, r.opts)
+		if (f == 0 && wroteNested) || (f > 0 && info != "") {
+			builder.WriteString("\n")
+		}
+		writeProtoComment(builder, info, indent+"  ")
+		key := field.Key()
+		name := protoFieldName(key)
+		builder.WriteString(indent + "  " + r.fieldType(fieldSchemaType(&field, r.structMap, r.opts.DurationFormat)) + " " + name + " = " +
+			strconv.Itoa(f+1))
+		var fieldOpts []string
+		if key != protoJSONName(name) {
+			fieldOpts = append(fieldOpts, "json_name = "+jsonString(key))
+		}
+		if field.IsDeprecated() {
+			fieldOpts = append(fieldOpts, "deprecated = true")
+		}
+		if len(fieldOpts) > 0 {
+			builder.WriteString(" [" + strings.Join(fieldOpts, ", ") + "]")
+		}
+		builder.WriteString(";\n")
+	}
+	builder.WriteString(indent + "}\n")
+}
+
+// fieldType returns the type of a field with the given schema, including the repeated label of a slice. Slices
+// and maps can't directly contain other slices or maps in proto, so their elements use protoValueType instead
+func (r *protoRenderer) fieldType(schema *schemaType) string {
+	switch {
+	case schema.Items != nil:
+		return "repeated " + r.elementType(schema.Items)
+	case schema.AdditionalProperties != nil:
+		return "map<string, " + r.elementType(schema.AdditionalProperties) + ">"
+	}
+	return r.elementType(schema)
+}
+
+// elementType returns the type of a slice element or map value with the given schema, or of a field if it is
+// neither
+func (r *protoRenderer) elementType(schema *schemaType) string {
+	if schema.Ref != "" {
+		return r.messages[schema.Ref]
+	}
+	switch schema.Type + "/" + schema.Format {
+	case "boolean/":
+		return "bool"
+	case "string/", "string/date-time":
+		return "string"
+	case "integer/int32":
+		return "int32"
+	case "integer/int64":
+		return "int64"
+	case "number/float":
+		return "float"
+	case "number/double":
+		return "double"
+	}
+	r.usesValue = true
+	return protoValueType
+}
+
+// protoMessageName returns the message name of a parsed struct, without the package qualifier of a struct from
+// another package (e.g. "Country" for "geoip.Country"), which would be read as a proto package
+func protoMessageName(structName string) string {
+	return structName[strings.LastIndex(structName, ".")+1:]
+}
+
+// protoFieldName returns a config key in lower snake case as recommended for proto field names, with an underscore
+// before each upper case letter that follows a lower case letter or digit or starts a word after an acronym of at
+// least two letters, e.g. site_name for SiteName, geo_ip_type for GeoIPType, and dbtype for DBtype
+func protoFieldName(key string) string {
+	runes := []rune(key)
+	var builder strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				(i > 1 && unicode.IsUpper(prev) && unicode.IsUpper(runes[i-2]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				builder.WriteRune('_')
+			}
+		}
+		builder.WriteRune(unicode.ToLower(r))
+	}
+	return builder.String()
+}
+
+// protoJSONName returns the JSON name that protobuf gives a field by default, which is its name in lower camel case
+// (e.g. siteName for site_name). Fields whose key in the config is different are given a json_name option
+func protoJSONName(name string) string {
+	var builder strings.Builder
+	upper := false
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+// writeProtoComment writes each line of text as a // comment at the given indent
+func writeProtoComment(builder *strings.Builder, text string, indent string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		builder.WriteString(strings.TrimRight(indent+"// "+line, " ") + "\n")
+	}
+}
@@ -0,0 +1,52 @@
+package cfgdoc
+
+import "strings"
+
+// QualifyStructs qualifies the names of the given structs, parsed from packages other than the one with the config
+// structs, with the names of their packages as they are written in the types of the config fields that use them
+// (e.g. geoip.Country for the Country struct of the geoip package), so that they can be rendered with the config
+// structs without colliding with structs of the same name. The types of their fields (and the structs they embed)
+// that refer to one of the given structs of the same package by its unqualified name are qualified the same way, so
+// that they still resolve. Structs without a Package are left as they are
+func QualifyStructs(structs []Struct) {
+	qualified := make(map[string]bool, len(structs))
+	for _, str := range structs {
+		if str.Package != "" {
+			qualified[str.Package+"."+str.Name] = true
+		}
+	}
+	qualify := func(pkg string, typ string) string {
+		name := referencedTypeName(typ)
+		if strings.Contains(name, ".") || !qualified[pkg+"."+name] {
+			return typ
+		}
+		return typ[:len(typ)-len(name)] + pkg + "." + name
+	}
+	for s := range structs {
+		str := &structs[s]
+		if str.Package == "" {
+			continue
+		}
+		for f := range str.Fields {
+			field := &str.Fields[f]
+			field.Type = qualify(str.Package, field.Type)
+			if field.Underlying != "" {
+				field.Underlying = qualify(str.Package, field.Underlying)
+			}
+			if field.Composite != "" {
+				field.Composite = qualify(str.Package, field.Composite)
+			}
+		}
+		for f := range str.Undocumented {
+			field := &str.Undocumented[f]
+			field.Type = qualify(str.Package, field.Type)
+			if field.Underlying != "" {
+				field.Underlying = qualify(str.Package, field.Underlying)
+			}
+		}
+		for e, embedded := range str.Embedded {
+			str.Embedded[e] = qualify(str.Package, embedded)
+		}
+		str.Name = str.Package + "." + str.Name
+	}
+}
@@ -0,0 +1,81 @@
+package cfgdoc
+
+import "strings"
+
+// schemaType is the JSON Schema/OpenAPI representation of a Go type. Exactly one of Type or Ref is set, unless the
+// type can hold any value (e.g. any), in which case neither is
+type schemaType struct {
+	Type   string // e.g. "integer" or "array"
+	Format string // e.g. "int64", only for some integer, number, and string types
+
+	// Ref is the name of the parsed struct the type refers to
+	Ref string
+
+	// Items is the element type of an array
+	Items *schemaType
+
+	// AdditionalProperties is the value type of a map, which is an object in JSON
+	AdditionalProperties *schemaType
+}
+
+// schemaTypeOf returns the schema of a type as shown in the Type column (e.g. "[]PageBanner" or
+// "map[string]int"), with time.Duration values in the given format. Structs in structMap are referenced by name,
+// and types that can't be mapped are treated as any
+func schemaTypeOf(typ string, structMap map[string]Struct, durations DurationFormat) *schemaType {
+	switch {
+	case strings.HasPrefix(typ, "*"):
+		// a pointer is set the same way as the type it points to, with null leaving it nil
+		return schemaTypeOf(typ[1:], structMap, durations)
+	case strings.HasPrefix(typ, "["):
+		end := strings.Index(typ, "]")
+		return &schemaType{Type: "array", Items: schemaTypeOf(typ[end+1:], structMap, durations)}
+	case strings.HasPrefix(typ, "map["):
+		end := strings.Index(typ, "]")
+		return &schemaType{Type: "object", AdditionalProperties: schemaTypeOf(typ[end+1:], structMap, durations)}
+	case typ == "time.Duration" && durations == DurationString:
+		return &schemaType{Type: "string"}
+	}
+	if _, ok := structMap[typ]; ok {
+		return &schemaType{Ref: typ}
+	}
+	switch typ {
+	case "bool":
+		return &schemaType{Type: "boolean"}
+	case "string":
+		return &schemaType{Type: "string"}
+	case "int8", "int16", "int32", "uint8", "uint16", "byte", "rune":
+		return &schemaType{Type: "integer", Format: "int32"}
+	case "int", "int64", "uint", "uint32", "uint64", "uintptr", "time.Duration":
+		// time.Duration is marshaled as a number of nanoseconds by default
+		return &schemaType{Type: "integer", Format: "int64"}
+	case "float32":
+		return &schemaType{Type: "number", Format: "float"}
+	case "float64":
+		return &schemaType{Type: "number", Format: "double"}
+	case "time.Time":
+		return &schemaType{Type: "string", Format: "date-time"}
+	}
+	return &schemaType{}
+}
+
+// fieldSchemaType returns the schema of a field's type, using the type it resolves to if it is a declared
+// non-struct type or alias
+func fieldSchemaType(field *Field, structMap map[string]Struct, durations DurationFormat) *schemaType {
+	if field.Underlying != "" {
+		return schemaTypeOf(field.Underlying, structMap, durations)
+	}
+	return schemaTypeOf(field.Type, structMap, durations)
+}
+
+// refs returns the names of the parsed structs referenced by the schema, including array items and map values
+func (s *schemaType) refs() []string {
+	switch {
+	case s.Ref != "":
+		return []string{s.Ref}
+	case s.Items != nil:
+		return s.Items.refs()
+	case s.AdditionalProperties != nil:
+		return s.AdditionalProperties.refs()
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package cfgdoc
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// TemplateData is the data that RenderTemplate executes a template with. The structs are the ones that RenderMarkdown
+// would render, with the See references of their fields resolved to Markdown links
+type TemplateData struct {
+	// Structs are all of the parsed structs, whether or not they are rendered
+	Structs []Struct
+
+	Header          string
+	Composite       []Struct // the structs of opts.CompositeStructs, which are at the top level of gochan.json
+	CompositeFooter string
+	Named           []Struct // opts.NamedStructs, followed by the structs referenced by the rendered fields
+	Sections        []TemplateSection
+	Enums           []Enum
+}
+
+// TemplateSection is one of Options.Sections with its parsed structs
+type TemplateSection struct {
+	Heading string
+	Text    string
+	Structs []Struct
+}
+
+// TemplateTable describes a table of fields, as returned by the table template function. HasFields is set if the
+// structs have any fields that aren't deprecated, and the column fields are set if the table rendered by
+// RenderMarkdown would have the column
+type TemplateTable struct {
+	Named     bool
+	Structs   []Struct
+	HasFields bool
+
+	// Groups are the groups of mutually exclusive fields with more than one field in the table
+	Groups []FieldGroup
+
+	BoardOption bool
+	Required    bool
+	Default     bool
+	Since       bool
+	Platform    bool
+}
+
+// RenderTemplate executes the text/template text with a TemplateData, so that formats other than the built-in ones
+// can be written without changing the tool. Besides the built-in functions, templates can call:
+//
+//   - table named structs: a TemplateTable of a Struct or []Struct, with the Board option column decided the same
+//     way as for the combined table if named is false
+//   - current fields: the fields that aren't deprecated, which are the ones written in the tables
+//   - deprecated fields: the deprecated fields
+//   - boardOption struct field: whether the field can be overridden in board.json
+//   - typeText field: the field's type as shown in the Type column
+//   - defaultText field: the field's default as shown in the Default column
+//   - jsonDefault field: the field's default (or the zero value of its type) as a JSON value, as in example configs
+//   - info field: the field's doc and annotations as shown in the Info column
+//   - markdownInfo field: the Info cell of the field in a Markdown table
+//   - fieldCell struct field: the Field cell of the field in a Markdown table, with its anchor if it has one
+//   - cell text: text escaped for a Markdown table cell
+//   - yesNo bool: "Yes" or "No"
+//   - joinOr values quote: the values wrapped in quote and joined into a list like "A, B, or C"
+func RenderTemplate(structs []Struct, opts Options, text string) (string, error) {
+	compositeStructs, namedStructs, sectionStructs := markdownStructs(structs, &opts)
+	data := TemplateData{
+		Structs:         structs,
+		Header:          opts.Header,
+		Composite:       compositeStructs,
+		CompositeFooter: opts.CompositeFooter,
+		Named:           namedStructs,
+		Enums:           opts.Enums,
+	}
+	for s, section := range opts.Sections {
+		data.Sections = append(data.Sections, TemplateSection{
+			Heading: section.Heading,
+			Text:    section.Text,
+			Structs: sectionStructs[s],
+		})
+	}
+
+	tmpl, err := template.New("cfgdoc").Funcs(templateFuncs(&opts)).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var builder strings.Builder
+	if err = tmpl.Execute(&builder, data); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+// templateFuncs returns the functions listed in the RenderTemplate doc
+func templateFuncs(opts *Options) template.FuncMap {
+	return template.FuncMap{
+		"table": func(named bool, structs any) (TemplateTable, error) {
+			var strs []Struct
+			switch structs := structs.(type) {
+			case Struct:
+				strs = []Struct{structs}
+			case []Struct:
+				strs = structs
+			default:
+				return TemplateTable{}, fmt.Errorf("table expects a Struct or []Struct, got %T", structs)
+			}
+			table := newFieldTable(opts, named, false, strs...)
+			return TemplateTable{
+				Named:       named,
+				Structs:     strs,
+				HasFields:   hasDocumentedFields(strs...),
+				Groups:      exclusiveGroups(strs...),
+				BoardOption: table.column("Board option") >= 0,
+				Required:    table.column("Required") >= 0,
+				Default:     table.column("Default") >= 0,
+				Since:       table.column("Since") >= 0,
+				Platform:    table.column("Platform") >= 0,
+			}, nil
+		},
+		"current": func(fields []Field) []Field {
+			return slices.DeleteFunc(slices.Clone(fields), func(f Field) bool { return f.IsDeprecated() })
+		},
+		"deprecated": func(fields []Field) []Field {
+			return slices.DeleteFunc(slices.Clone(fields), func(f Field) bool { return !f.IsDeprecated() })
+		},
+		"boardOption": func(str Struct, field Field) bool {
+			return str.IsBoardOption(&field, opts.BoardStructs)
+		},
+		"typeText": func(field Field) string {
+			return field.TypeText(opts.ResolveAliases)
+		},
+		"defaultText": func(field Field) string {
+			return defaultCell(&field, opts)
+		},
+		"jsonDefault": func(field Field) string {
+			return jsonValue(&field, opts.structs, opts.DurationFormat)
+		},
+		"info": func(field Field) string {
+			return infoText(&field, opts)
+		},
+		"markdownInfo": func(field Field) string {
+			return wrapMarkdownCell(markdownInfoText(&field, opts), opts.WrapWidth)
+		},
+		"fieldCell": func(str Struct, field Field) string {
+			return markdownFieldCell(&str, &field, opts)
+		},
+		"cell":   markdownCellText,
+		"yesNo":  yesNo,
+		"joinOr": joinOr,
+	}
+}
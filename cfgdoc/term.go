@@ -0,0 +1,151 @@
+package cfgdoc
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	ansiBold    = "\x1b[1m"
+	ansiDim     = "\x1b[2m"
+	ansiDefault = "\x1b[32m" // green, for the Default column
+	ansiReset   = "\x1b[0m"
+
+	// maxTermDefaultLength is the width that the Default column is limited to in terminal output, longer defaults
+	// are wrapped
+	maxTermDefaultLength = 24
+
+	// minTermInfoWidth is the width that the Info column is given even if the table doesn't fit the terminal
+	minTermInfoWidth = 20
+)
+
+// RenderTerminal renders a table for each of opts.CompositeStructs and opts.NamedStructs for reading in a terminal
+// of the given width, with the columns aligned with spaces and the Default and Info columns wrapped to fit. Unlike
+// the Markdown tables, deprecated fields are included. If color is true, struct headings are bold, deprecated fields
+// are dimmed, and defaults are colored using ANSI escape sequences
+func RenderTerminal(structs []Struct, opts Options, width int, color bool) string {
+	structMap := tableStructs(structs, &opts)
+	opts.structs = structMap
+	var builder strings.Builder
+	style := func(ansi string, text string) string {
+		if !color || text == "" {
+			return text
+		}
+		return ansi + text + ansiReset
+	}
+
+	compositeStructs := compositeStructList(structMap, &opts)
+	renderedStructs := slices.Clone(compositeStructs)
+	for _, structName := range namedStructNames(structMap, &opts) {
+		if str, ok := structMap[structName]; ok {
+			renderedStructs = append(renderedStructs, str)
+		}
+	}
+	for s, str := range renderedStructs {
+		named := s >= len(compositeStructs)
+		if s > 0 {
+			builder.WriteString("\n")
+		}
+		builder.WriteString(style(ansiBold, str.Name) + "\n")
+		if doc := strings.Join(strings.Fields(str.Doc), " "); doc != "" {
+			builder.WriteString(strings.Join(wrapWords(doc, width), "\n") + "\n")
+		}
+		if len(str.Fields) == 0 {
+			builder.WriteString(noDocumentedFields + "\n")
+			continue
+		}
+
+		table := newFieldTable(&opts, named, false, str)
+		for _, header := range []string{"Field", "Type"} {
+			// newFieldTable only fits the non-deprecated fields
+			c := table.column(header)
+			for _, field := range str.Fields {
+				table.lengths[c] = max(table.lengths[c], utf8.RuneCountInString(table.columns[c].text(&opts, &str, &field)))
+			}
+		}
+		defaultColumn := table.column("Default")
+		if defaultColumn >= 0 {
+			table.lengths[defaultColumn] = min(table.lengths[defaultColumn], maxTermDefaultLength)
+		}
+		widths := table.widths()
+		infoWidth := width
+		for _, w := range widths[:len(widths)-1] {
+			infoWidth -= w + 1
+		}
+		infoWidth = max(infoWidth, minTermInfoWidth)
+
+		writeTermRow(&builder, widths, color, "", table.headers())
+		for _, field := range str.Fields {
+			cells := table.textCells(&str, &field)
+			if defaultColumn >= 0 {
+				cells[defaultColumn] = strings.Join(wrapWords(cells[defaultColumn], table.lengths[defaultColumn]), "\n")
+			}
+			cells = append(cells, strings.Join(wrapWords(infoText(&field, &opts), infoWidth), "\n"))
+
+			if field.IsDeprecated() {
+				writeTermRow(&builder, widths, color, ansiDim, cells)
+				continue
+			}
+			if color && defaultColumn >= 0 {
+				// color each line of the default separately, so that padding and line breaks aren't colored
+				lines := strings.Split(cells[defaultColumn], "\n")
+				for l, line := range lines {
+					lines[l] = style(ansiDefault, line)
+				}
+				cells[defaultColumn] = strings.Join(lines, "\n")
+			}
+			writeTermRow(&builder, widths, color, "", cells)
+		}
+	}
+	return builder.String()
+}
+
+// writeTermRow writes a row of cells separated by spaces, padding every cell but the last to the width of its
+// column. Cells with more than one line are continued on the following lines, with the other cells left blank. If
+// color is true and rowStyle is set, each line of the row is wrapped in that ANSI style. Escape sequences in cells
+// aren't counted in their width
+func writeTermRow(builder *strings.Builder, widths []int, color bool, rowStyle string, cells []string) {
+	cellLines := make([][]string, len(cells))
+	numLines := 1
+	for c, cell := range cells {
+		cellLines[c] = strings.Split(cell, "\n")
+		numLines = max(numLines, len(cellLines[c]))
+	}
+	for l := range numLines {
+		var line strings.Builder
+		for c := range cells {
+			var text string
+			if l < len(cellLines[c]) {
+				text = cellLines[c][l]
+			}
+			line.WriteString(text)
+			if c < len(cells)-1 {
+				line.WriteString(strings.Repeat(" ", max(widths[c]-visibleLength(text), 0)+1))
+			}
+		}
+		text := strings.TrimRight(line.String(), " ")
+		if color && rowStyle != "" {
+			text = rowStyle + text + ansiReset
+		}
+		builder.WriteString(text + "\n")
+	}
+}
+
+// visibleLength returns the number of characters in s that are shown in a terminal, not counting ANSI escape
+// sequences like "\x1b[1m"
+func visibleLength(s string) int {
+	length := 0
+	inEscape := false
+	for _, r := range s {
+		switch {
+		case r == '\x1b':
+			inEscape = true
+		case inEscape:
+			inEscape = r < '@' || r > '~' || r == '['
+		default:
+			length++
+		}
+	}
+	return length
+}
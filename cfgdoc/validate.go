@@ -0,0 +1,332 @@
+package cfgdoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ValidateDefaults returns a message for each field of the given structs whose Default annotation obviously can't
+// be a value of its type, e.g. a non-numeric default on an int field or one that isn't true or false on a bool
+// field. The check is deliberately conservative: only numeric and bool types are checked, and only the first word
+// of the default, so that defaults followed by an explanation like "0 (unlimited)" aren't reported. Defaults that
+// differ from the value assigned in code (see SetDefaults) are also reported. Platform defaults are checked the same
+// way, but not compared with the code, which only has the default of the platform it is built for
+func ValidateDefaults(structs []Struct) []string {
+	var mismatches []string
+	for _, str := range structs {
+		for _, field := range str.Fields {
+			typ := structTypeName(&field)
+			for _, platform := range field.defaultPlatforms() {
+				platformDefault := field.PlatformDefaults[platform]
+				if def, _, _ := strings.Cut(platformDefault, " "); !validDefault(typ, def) {
+					mismatches = append(mismatches, fmt.Sprintf("%s.%s (%s): %s default %q is not a valid %s value",
+						str.Name, field.Name, field.Source(), platform, platformDefault, typ))
+				}
+			}
+
+			def, _, _ := strings.Cut(strings.TrimSpace(field.Default), " ")
+			if def == "" {
+				continue
+			}
+			if !validDefault(typ, def) {
+				mismatches = append(mismatches, fmt.Sprintf("%s.%s (%s): default %q is not a valid %s value",
+					str.Name, field.Name, field.Source(), field.Default, typ))
+			} else if defaultsDiffer(&field) {
+				mismatches = append(mismatches, fmt.Sprintf("%s.%s (%s): default %q doesn't match the value %q assigned in code",
+					str.Name, field.Name, field.Source(), field.Default, field.CodeDefault))
+			}
+		}
+	}
+	return mismatches
+}
+
+// validDefault returns false if def can't be parsed as a value of typ. Types other than bool and the numeric types
+// are always considered valid
+func validDefault(typ string, def string) bool {
+	var err error
+	switch typ {
+	case "bool":
+		_, err = strconv.ParseBool(def)
+	case "int", "int8", "int16", "int32", "int64", "rune":
+		_, err = strconv.ParseInt(def, 0, 64)
+	case "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte":
+		_, err = strconv.ParseUint(def, 0, 64)
+	case "float32", "float64":
+		_, err = strconv.ParseFloat(def, 64)
+	}
+	return err == nil
+}
+
+// ValidateConfig checks a config file like gochan.json against the structs that opts.CompositeStructs are parsed
+// from, returning a message for each key that isn't a field, documented or not (which is likely a typo), each
+// required field that is missing, each deprecated field that is set, and each value that can't be decoded into its
+// field's type, e.g. a string given for an int field. Objects for fields whose type is a parsed struct, and the
+// elements of slices and maps of them, are checked the same way. Keys are matched case-insensitively, like
+// encoding/json does, and time.Duration values are expected in opts.DurationFormat. The returned error is only
+// non-nil if data isn't a valid JSON object
+func ValidateConfig(structs []Struct, opts Options, data []byte) ([]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var config map[string]any
+	if err := decoder.Decode(&config); err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, errors.New("expected a JSON object")
+	}
+
+	validator := configValidator{structMap: structsByName(structs), durations: opts.DurationFormat}
+	for name, str := range validator.structMap {
+		// undocumented fields are still read by gochan, so they aren't unknown keys
+		str.Fields = append(slices.Clip(str.Fields), str.Undocumented...)
+		validator.structMap[name] = str
+	}
+	var fields []Field
+	for _, structName := range opts.CompositeStructs {
+		if str, ok := validator.structMap[structName]; ok {
+			// embedded structs are listed in opts.CompositeStructs themselves
+			fields = append(fields, str.Fields...)
+		}
+	}
+	validator.object("", config, fields, opts.CompositeStructs)
+	return validator.problems, nil
+}
+
+type configValidator struct {
+	structMap map[string]Struct
+	durations DurationFormat
+	problems  []string
+}
+
+// problem adds a message about the value at the given key path, e.g. "Captcha.SiteKey" or "Styles[0].Name"
+func (v *configValidator) problem(path string, format string, args ...any) {
+	if path == "" {
+		path = "(root)"
+	}
+	v.problems = append(v.problems, path+": "+fmt.Sprintf(format, args...))
+}
+
+// object checks the members of a JSON object, in sorted order, against the given fields. parents holds the structs
+// being checked, so that a struct that contains itself isn't checked forever
+func (v *configValidator) object(path string, object map[string]any, fields []Field, parents []string) {
+	known := make(map[string]*Field)
+	for f := range fields {
+		field := &fields[f]
+		if field.Name == "" || field.Unexported || field.JSONName == "-" {
+			continue
+		}
+		if _, ok := known[strings.ToLower(field.Key())]; !ok {
+			known[strings.ToLower(field.Key())] = field
+		}
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	found := make(map[*Field]bool)
+	for _, key := range keys {
+		keyPath := key
+		if path != "" {
+			keyPath = path + "." + key
+		}
+		field, ok := known[strings.ToLower(key)]
+		if !ok {
+			// a field renamed by its json struct tag is only found by its tag's name
+			if renamed := slices.IndexFunc(fields, func(field Field) bool {
+				return field.JSONName != "" && field.JSONName != "-" && strings.EqualFold(field.Name, key)
+			}); renamed >= 0 {
+				v.problem(keyPath, "unknown key, did you mean %s?", fields[renamed].JSONName)
+			} else {
+				v.problem(keyPath, "unknown key")
+			}
+			continue
+		}
+		found[field] = true
+		if field.IsDeprecated() {
+			v.problem(keyPath, "%s is deprecated", field.Name)
+		}
+		v.value(keyPath, object[key], fieldSchemaType(field, v.structMap, v.durations), parents)
+	}
+
+	for f := range fields {
+		field := &fields[f]
+		if field.Required && known[strings.ToLower(field.Key())] == field && !found[field] {
+			keyPath := field.Key()
+			if path != "" {
+				keyPath = path + "." + keyPath
+			}
+			v.problem(keyPath, "missing required key")
+		}
+	}
+}
+
+// value checks a JSON value against the schema of its field's type. null is accepted for any type, since it leaves
+// the field unset
+func (v *configValidator) value(path string, val any, schema *schemaType, parents []string) {
+	if val == nil {
+		return
+	}
+	var matches bool
+	switch {
+	case schema.Ref != "":
+		object, ok := val.(map[string]any)
+		if matches = ok; ok && !slices.Contains(parents, schema.Ref) {
+			str := v.structMap[schema.Ref]
+			v.object(path, object, promotedFields(&str, v.structMap, []string{str.Name}), append(parents, schema.Ref))
+		}
+	case schema.Items != nil:
+		items, ok := val.([]any)
+		matches = ok
+		for i, item := range items {
+			v.value(path+"["+strconv.Itoa(i)+"]", item, schema.Items, parents)
+		}
+	case schema.AdditionalProperties != nil:
+		object, ok := val.(map[string]any)
+		matches = ok
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			v.value(path+"."+key, object[key], schema.AdditionalProperties, parents)
+		}
+	case schema.Type == "boolean":
+		_, matches = val.(bool)
+	case schema.Type == "string":
+		_, matches = val.(string)
+	case schema.Type == "integer":
+		number, ok := val.(json.Number)
+		_, err := number.Int64()
+		matches = ok && err == nil
+	case schema.Type == "number":
+		_, matches = val.(json.Number)
+	default:
+		// e.g. any
+		matches = true
+	}
+	if !matches {
+		v.problem(path, "expected %s, got %s", schemaTypeName(schema), jsonTypeName(val))
+	}
+}
+
+// schemaTypeName returns the name of a schema's JSON type in messages, e.g. "an integer" or "an object"
+func schemaTypeName(schema *schemaType) string {
+	switch {
+	case schema.Ref != "", schema.AdditionalProperties != nil:
+		return "an object"
+	case schema.Items != nil:
+		return "an array"
+	case schema.Type == "integer":
+		return "an integer"
+	}
+	return "a " + schema.Type
+}
+
+// jsonTypeName returns the name of the type of a decoded JSON value in messages, e.g. "a string"
+func jsonTypeName(val any) string {
+	switch val := val.(type) {
+	case bool:
+		return "a boolean"
+	case string:
+		return "a string"
+	case json.Number:
+		if _, err := val.Int64(); err == nil {
+			return "an integer"
+		}
+		return "a number"
+	case []any:
+		return "an array"
+	}
+	return "an object"
+}
+
+// CheckStyle returns a message for each field of the given structs whose doc isn't written as complete sentences:
+// after collapsing it into a single line, it should start with an upper case letter (or the field's own name) and
+// end with a period, exclamation mark, or question mark. Docs longer than maxLength characters are also reported,
+// unless maxLength is 0
+func CheckStyle(structs []Struct, maxLength int) []string {
+	var problems []string
+	for _, str := range structs {
+		for _, field := range str.Fields {
+			doc := strings.Join(strings.Fields(field.Doc), " ")
+			if doc == "" {
+				// e.g. a field whose doc only has annotations
+				continue
+			}
+			report := func(format string, args ...any) {
+				problems = append(problems, fmt.Sprintf("%s.%s (%s): ", str.Name, field.Name, field.Source())+
+					fmt.Sprintf(format, args...))
+			}
+			if first, _ := utf8.DecodeRuneInString(doc); unicode.IsLower(first) && !strings.HasPrefix(doc, field.Name) {
+				report("doc starts with a lower case letter")
+			}
+			if !strings.ContainsAny(doc[len(doc)-1:], ".!?") {
+				report("doc doesn't end with punctuation")
+			}
+			if length := utf8.RuneCountInString(doc); maxLength > 0 && length > maxLength {
+				report("doc is %d characters long, more than %d", length, maxLength)
+			}
+		}
+	}
+	return problems
+}
+
+// ValidateStructure checks that compositeStructs, which are documented as the top level of the config, are the
+// structs that the top-level config struct topLevel embeds, directly or through the structs it embeds. It returns a
+// message for each composite struct that isn't embedded, each embedded struct that isn't in compositeStructs, and
+// each documented field declared in topLevel itself, which wouldn't be in the combined table
+func ValidateStructure(structs []Struct, topLevel string, compositeStructs []string) []string {
+	structMap := structsByName(structs)
+	top, ok := structMap[topLevel]
+	if !ok {
+		return []string{fmt.Sprintf("top-level config struct %s not found", topLevel)}
+	}
+
+	var problems []string
+	for _, field := range top.Fields {
+		if field.Name != "" && !field.Unexported {
+			problems = append(problems, fmt.Sprintf("%s.%s (%s) is declared in %s instead of one of the composite structs",
+				topLevel, field.Name, field.Source(), topLevel))
+		}
+	}
+
+	// the embedded structs in the order they are found, breadth first, so that the messages follow the declarations
+	var embedded []string
+	embeddedBy := make(map[string]string)
+	queue := []string{topLevel}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, embeddedName := range structMap[name].Embedded {
+			if _, seen := embeddedBy[embeddedName]; seen || embeddedName == topLevel {
+				continue
+			}
+			embeddedBy[embeddedName] = name
+			embedded = append(embedded, embeddedName)
+			queue = append(queue, embeddedName)
+		}
+	}
+
+	for _, name := range embedded {
+		if !slices.Contains(compositeStructs, name) {
+			problems = append(problems, fmt.Sprintf("%s is embedded in %s but isn't one of the composite structs",
+				name, embeddedBy[name]))
+		}
+	}
+	for _, name := range compositeStructs {
+		if _, ok := embeddedBy[name]; !ok {
+			problems = append(problems, fmt.Sprintf("composite struct %s isn't embedded in %s", name, topLevel))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,56 @@
+package cfgdoc
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+// ParseStringList parses the non-test Go files in dir for the package-level variable with the given name, and
+// returns the string literals in its value, e.g. the locales in
+//
+//	var SupportedLocales = []string{"de", "en", "es"}
+//
+// It returns an error if there is no such variable or if its value isn't a list of string literals
+func ParseStringList(dir string, name string) ([]string, error) {
+	paths, err := goFiles(dir, nil)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		file, err := parseFile(fset, path, path)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+				for n, ident := range valueSpec.Names {
+					if ident.Name != name || n >= len(valueSpec.Values) {
+						continue
+					}
+					lit, ok := valueSpec.Values[n].(*ast.CompositeLit)
+					if !ok {
+						return nil, fmt.Errorf("%s in %s isn't a list literal", name, dir)
+					}
+					values := make([]string, 0, len(lit.Elts))
+					for _, elt := range lit.Elts {
+						basicLit, ok := elt.(*ast.BasicLit)
+						if !ok || basicLit.Kind != token.STRING {
+							return nil, fmt.Errorf("%s in %s has a value that isn't a string literal", name, dir)
+						}
+						val, _ := literalValue(basicLit)
+						values = append(values, val)
+					}
+					return values, nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("no variable named %s found in %s", name, dir)
+}
@@ -0,0 +1,98 @@
+package cfgdoc
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RenderYAML renders an example configuration as a YAML document with one key per non-deprecated field of
+// opts.CompositeStructs, set to the same value as RenderExampleJSON and preceded by the field's doc as a comment.
+// Fields whose type is a parsed struct are written as nested maps of that struct's fields, including the fields of
+// the structs it embeds
+func RenderYAML(structs []Struct, opts Options) string {
+	structMap := structsByName(structs)
+	var builder strings.Builder
+	written := make(map[string]bool)
+	for _, structName := range opts.CompositeStructs {
+		str, ok := structMap[structName]
+		if !ok {
+			continue
+		}
+		var fields []Field
+		for _, field := range str.Fields {
+			// embedded structs are listed in opts.CompositeStructs themselves, and a field that is declared in more
+			// than one of them is only written once
+			if field.Name != "" && !written[field.Key()] {
+				fields = append(fields, field)
+				written[field.Key()] = true
+			}
+		}
+		yamlFields(&builder, fields, structMap, &opts, "", []string{structName})
+	}
+	return builder.String()
+}
+
+// yamlFields writes a key for each of the given non-deprecated, exported fields at the given indent, named by its
+// JSON name. parents holds the structs being expanded, so that a struct that contains itself isn't expanded forever
+func yamlFields(builder *strings.Builder, fields []Field, structMap map[string]Struct, opts *Options, indent string, parents []string) {
+	for _, field := range fields {
+		if field.IsDeprecated() || field.Unexported {
+			continue
+		}
+		if info := field.Info(); info != "" {
+			builder.WriteString(indent + "# " + info + "\n")
+		}
+		builder.WriteString(indent + yamlString(field.Key()) + ":")
+
+		typ := structTypeName(&field)
+		if str, ok := structMap[typ]; ok {
+			if !slices.Contains(parents, typ) && hasDocumentedFields(str) {
+				builder.WriteString("\n")
+				// the fields of structs embedded in str are at the same level as its own fields
+				yamlFields(builder, promotedFields(&str, structMap, parents), structMap, opts, indent+"  ", append(parents, typ))
+			} else {
+				builder.WriteString(" {}\n")
+			}
+			continue
+		}
+		builder.WriteString(" " + yamlValue(jsonValue(&field, structMap, opts.DurationFormat)) + "\n")
+	}
+}
+
+// yamlValue converts a JSON value returned by jsonValue to YAML. Strings are written as plain scalars where that
+// is unambiguous, other values (including arrays and objects, as flow collections) are already valid YAML
+func yamlValue(jsonVal string) string {
+	var s string
+	if !strings.HasPrefix(jsonVal, `"`) || json.Unmarshal([]byte(jsonVal), &s) != nil {
+		return jsonVal
+	}
+	return yamlString(s)
+}
+
+// yamlString returns s as a plain YAML scalar if it would be read back as the same string, or as a double-quoted
+// scalar otherwise (e.g. if it is empty, looks like a number or boolean, or contains characters with special
+// meaning). JSON string escapes are also valid in YAML double-quoted scalars
+func yamlString(s string) string {
+	if s == "" || strings.TrimSpace(s) != s || strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") ||
+		strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return jsonString(s)
+	}
+	for _, r := range s {
+		if r < ' ' || r == 0x7f {
+			return jsonString(s)
+		}
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null", "~", ".inf", ".nan":
+		return jsonString(s)
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return jsonString(s)
+	}
+	if _, err := strconv.ParseInt(s, 0, 64); err == nil {
+		return jsonString(s)
+	}
+	return s
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change in a unified diff
+const diffContext = 3
+
+type diffOp struct {
+	kind byte // ' ' for unchanged lines, '-' for removed lines, '+' for added lines
+	line string
+}
+
+func splitDiffLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns the edit script turning a into b, using the longest common subsequence of their lines
+func diffLines(a, b []string) []diffOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+// unifiedDiff returns a unified diff of the lines in from and to, or an empty string if they are identical
+func unifiedDiff(fromName, toName, from, to string) string {
+	if from == to {
+		return ""
+	}
+	ops := diffLines(splitDiffLines(from), splitDiffLines(to))
+
+	// fromPos[k] and toPos[k] are the number of lines of from and to that precede ops[k]
+	fromPos := make([]int, len(ops)+1)
+	toPos := make([]int, len(ops)+1)
+	for k, op := range ops {
+		fromPos[k+1] = fromPos[k]
+		toPos[k+1] = toPos[k]
+		if op.kind != '+' {
+			fromPos[k+1]++
+		}
+		if op.kind != '-' {
+			toPos[k+1]++
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString("--- " + fromName + "\n+++ " + toName + "\n")
+	for k := 0; k < len(ops); {
+		for k < len(ops) && ops[k].kind == ' ' {
+			k++
+		}
+		if k == len(ops) {
+			break
+		}
+		start := max(k-diffContext, 0)
+		end := k
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			unchanged := end
+			for unchanged < len(ops) && ops[unchanged].kind == ' ' {
+				unchanged++
+			}
+			if unchanged == len(ops) || unchanged-end > 2*diffContext {
+				end = min(end+diffContext, len(ops))
+				break
+			}
+			end = unchanged
+		}
+
+		fromStart, fromCount := fromPos[start], fromPos[end]-fromPos[start]
+		toStart, toCount := toPos[start], toPos[end]-toPos[start]
+		if fromCount > 0 {
+			fromStart++
+		}
+		if toCount > 0 {
+			toStart++
+		}
+		fmt.Fprintf(&builder, "@@ -%d,%d +%d,%d @@\n", fromStart, fromCount, toStart, toCount)
+		for _, op := range ops[start:end] {
+			builder.WriteByte(op.kind)
+			builder.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				builder.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		k = end
+	}
+	return builder.String()
+}
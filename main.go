@@ -1,15 +1,23 @@
+//go:generate go test -run TestGolden -update
+
 package main
 
 import (
+	"flag"
 	"fmt"
-	"go/ast"
-	"go/parser"
-	"go/token"
-	"io/fs"
+	"io"
 	"os"
 	"path"
 	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/gochan-org/gochan-cfgdoc/cfgdoc"
 )
 
 const (
@@ -21,358 +29,869 @@ See [gochan.example.json](examples/configs/gochan.example.json) for an example g
 Fields in the table marked as board options can be overridden on individual boards by adding them to  board.json, which gochan looks for in the board directory or in the same directory as gochan.json.
 
 `
+
+	// geoipOptionsStruct is the struct in the geoip package documenting the GeoIPOptions accepted by the
+	// geoipOptionsType handler, used to generate the GeoIPOptions example
+	geoipOptionsStruct = "geoip.MMDBOptions"
+	geoipOptionsType   = "mmdb"
+
+	// geoipLocalesVar is the variable in the geoip package listing the supported locales, shown as the allowed
+	// values of the geoipLocaleKey option by -locales
+	geoipLocalesVar = "SupportedLocales"
+	geoipLocaleKey  = "isoCode"
+
+	// geoipSection is the heading of the section with the GeoIPOptions example, customFlagsExample, and the tables
+	// of geoipSectionStructs in Markdown output
+	geoipSection = "GeoIP"
+	geoipIntro   = "Posts can show the country of the poster's IP address as a flag, looked up in the database set by the `GeoIPType` and `GeoIPOptions` options in gochan.json, or a custom flag selected by the poster if `CustomFlags` is set for the board.\n\n"
+
+	// customFlagsExample is left out with -expand-slices, which documents the geoip.Country fields after the
+	// CustomFlags field instead
+	customFlagsExample = "`CustomFlags` is an array with custom post flags, selectable via dropdown. The `Flag` value is assumed to be a file in /static/flags/. Example:\n" +
+		"```JSON\n" +
+		"\"CustomFlags\": [\n" +
+		"\t{\"Flag\":\"california.png\", \"Name\": \"California\"},\n" +
+		"\t{\"Flag\":\"cia.png\", \"Name\": \"CIA\"},\n" +
+		"\t{\"Flag\":\"lgbtq.png\", \"Name\": \"LGBTQ\"},\n" +
+		"\t{\"Flag\":\"ms-dos.png\", \"Name\": \"MS-DOS\"},\n" +
+		"\t{\"Flag\":\"stallman.png\", \"Name\": \"Stallman\"},\n" +
+		"\t{\"Flag\":\"templeos.png\", \"Name\": \"TempleOS\"},\n" +
+		"\t{\"Flag\":\"tux.png\", \"Name\": \"Linux\"},\n" +
+		"\t{\"Flag\":\"windows9x.png\", \"Name\": \"Windows 9x\"}\n" +
+		"]\n```\n"
+
+	// compositeHeading is the heading given to the combined table by -format markdown-anchors
+	compositeHeading = "Configuration options"
+
+	formatMarkdown            = "markdown"
+	formatMarkdownCollapsible = "markdown-collapsible"
+	formatMarkdownAnchors     = "markdown-anchors"
+	formatMarkdownMinimal     = "markdown-minimal"
+	formatMarkdownAdmonitions = "markdown-github-admonitions"
+	formatMarkdownFootnotes   = "markdown-footnotes"
+	formatMarkdownSplit       = "markdown-split"
+	formatHTML                = "html"
+	formatCSV                 = "csv"
+	formatTSV                 = "tsv"
+	formatExampleJSON         = "example-json"
+	formatMan                 = "man"
+	formatYAML                = "yaml"
+	formatOpenAPI             = "openapi"
+	formatProto               = "proto"
+	formatDotenv              = "dotenv"
+	formatTerm                = "term"
+
+	// defaultTermWidth is the width that -format term output is wrapped to if the terminal's width can't be found
+	defaultTermWidth = 80
+)
+
+// configStructType is the top-level config struct that gochan.json is decoded into, checked against
+// compositeStructTypes by -validate-structure
+const configStructType = "GochanConfig"
+
+// exit codes, so that scripts can tell why the tool failed
+const (
+	exitSuccess = 0
+
+	// exitUsage is used for invalid flags or arguments, and if the output can't be written
+	exitUsage = 1
+
+	// exitParseError is used if the gochan tree or a config being validated can't be read or parsed
+	exitParseError = 2
+
+	// exitValidationFailed is used if -check finds a difference or if -validate-defaults, -validate-config,
+	// -validate-structure, or -style-strict finds a problem
+	exitValidationFailed = 3
 )
 
+// version is the version of the tool printed by -version, set when building a release with
+// -ldflags "-X main.version=v1.2.3". Otherwise the module version is used if it was installed with go install
+var version = ""
+
+// toolVersion returns version, or the version of the main module from the build info if it isn't set
+func toolVersion() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
+
 var (
+	// compositeStructTypes are the structs that configStructType embeds, whose fields are at the top level of
+	// gochan.json. The structs that they embed in turn are added by cfgdoc.ExpandComposites
 	compositeStructTypes = []string{
 		"SystemCriticalConfig", "SQLConfig", "SiteConfig", "BoardConfig", "PostConfig", "UploadConfig",
 	}
 	explicitlyNamedStructTypes = []string{
-		"CaptchaConfig", "PageBanner", "BoardCooldowns",
+		"CaptchaConfig", "PageBanner", "BoardCooldowns", "geoip.Country",
+	}
+	// geoipSectionStructs are written in the GeoIP section instead of with the other named structs in Markdown
+	// output
+	geoipSectionStructs = []string{"geoip.Country"}
+	// boardStructTypes lists the structs whose fields can be overridden in board.json, unless a struct's doc
+	// comment says otherwise via a BoardOption annotation. It can be replaced with the -board-structs flag
+	boardStructTypes = []string{
+		"BoardConfig", "PostConfig", "UploadConfig",
+	}
+	outputFormats = []string{
+		formatMarkdown, formatMarkdownCollapsible, formatMarkdownAnchors, formatMarkdownMinimal, formatMarkdownAdmonitions,
+		formatMarkdownFootnotes, formatMarkdownSplit,
+		formatHTML,
+		formatCSV, formatTSV, formatExampleJSON, formatYAML, formatOpenAPI, formatProto, formatMan,
+		formatDotenv, formatTerm,
 	}
 )
 
-type columnLengths struct {
-	fieldLength   int
-	typeLength    int
-	defaultLength int
-	docLength     int
+// geoipOptionsExample returns the example GeoIPOptions written in the GeoIP section, generated from the fields of
+// geoipOptionsStruct with durations in the given format, or an empty string if it wasn't found, with a warning
+// written to warnings
+func geoipOptionsExample(geoipStructs []cfgdoc.Struct, durations cfgdoc.DurationFormat, warnings io.Writer) string {
+	i := slices.IndexFunc(geoipStructs, func(str cfgdoc.Struct) bool { return str.Name == geoipOptionsStruct })
+	if i < 0 {
+		fmt.Fprintf(warnings, "Warning: struct %s not found, leaving out the GeoIPOptions example\n", geoipOptionsStruct)
+		return ""
+	}
+	return "Example options for `GeoIPOptions`:\n" +
+		"```JSONC\n" +
+		"\"GeoIPType\": \"" + geoipOptionsType + "\",\n" +
+		"\"GeoIPOptions\": {\n" +
+		cfgdoc.JSONCMembers(&geoipStructs[i], "\t", durations) +
+		"}\n```\n"
 }
 
-func (c *columnLengths) setLengths(strs ...structType) {
-	c.fieldLength = 6
-	c.typeLength = 5
-	c.defaultLength = 0
-	c.docLength = 4
-	for _, str := range strs {
-		for _, field := range str.fields {
-			if len(field.name) > c.fieldLength {
-				c.fieldLength = len(field.name)
-			}
-			if len(field.fType) > c.typeLength {
-				c.typeLength = len(field.fType)
-			}
-			if len(field.defaultVal) > c.defaultLength {
-				c.defaultLength = len(field.defaultVal)
-			}
-			if len(field.doc) > c.docLength {
-				c.docLength = len(field.doc)
-			}
-		}
+// parseTree parses the config and geoip packages in the given directories (relative to gochanRoot) with the given
+// options, returning the config structs followed by the geoip structs, and the geoip structs on their own. The geoip
+// structs are qualified with their package (e.g. geoip.Country), as they are named in the config fields. If
+// parseOpts.MarkedOnly is set, the whole tree is parsed instead (see parseMarkedStructs). Field positions are
+// relative to gochanRoot, e.g. pkg/config/config.go:42
+func parseTree(gochanRoot, configDir, geoipDir string, parseOpts cfgdoc.ParseOptions) ([]cfgdoc.Struct, []cfgdoc.Struct, error) {
+	cfgDir := path.Join(gochanRoot, configDir)
+	geoipDir = path.Join(gochanRoot, geoipDir)
+	if parseOpts.MarkedOnly {
+		return parseMarkedStructs(gochanRoot, cfgDir, geoipDir, parseOpts)
+	}
+	var configStructs, geoipStructs []cfgdoc.Struct
+	var cfgErr, geoipErr error
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		configStructs, cfgErr = cfgdoc.ParseWith(cfgDir, parseOpts)
+	}()
+	go func() {
+		defer wg.Done()
+		geoipStructs, geoipErr = cfgdoc.ParseWith(geoipDir, parseOpts)
+	}()
+	wg.Wait()
+	if cfgErr != nil {
+		return nil, nil, fmt.Errorf("parsing package in %s: %w", cfgDir, cfgErr)
 	}
-	if c.defaultLength > 0 && c.defaultLength < 8 {
-		c.defaultLength = 8
+	if geoipErr != nil {
+		return nil, nil, fmt.Errorf("parsing package in %s: %w", geoipDir, geoipErr)
 	}
+
+	cfgdoc.QualifyStructs(geoipStructs)
+	structs := slices.Concat(configStructs, geoipStructs)
+	relativePaths(gochanRoot, structs)
+	return structs, geoipStructs, nil
 }
 
-func mustParse(fset *token.FileSet, filename, filePath string) *ast.File {
-	ba, err := os.ReadFile(filePath)
+// parseMarkedStructs parses every package under gochanRoot for the structs marked with the cfgdoc.ConfigDirective,
+// and the structs they use, returning all of them and the ones declared in geoipDir. The structs declared outside of
+// cfgDir are qualified with their package (e.g. geoip.Country) and come after the config structs
+func parseMarkedStructs(gochanRoot, cfgDir, geoipDir string, parseOpts cfgdoc.ParseOptions) ([]cfgdoc.Struct, []cfgdoc.Struct, error) {
+	parsed, err := cfgdoc.ParseWith(gochanRoot, parseOpts)
 	if err != nil {
-		panic(err)
+		return nil, nil, fmt.Errorf("parsing packages in %s: %w", gochanRoot, err)
+	}
+	inDir := func(dir string, str cfgdoc.Struct) bool {
+		rel, err := filepath.Rel(dir, str.File)
+		return err == nil && !strings.HasPrefix(rel, "..")
+	}
+	var structs, otherStructs, geoipStructs []cfgdoc.Struct
+	for _, str := range parsed {
+		if inDir(cfgDir, str) {
+			structs = append(structs, str)
+		} else {
+			otherStructs = append(otherStructs, str)
+		}
+	}
+	cfgdoc.QualifyStructs(otherStructs)
+	for _, str := range otherStructs {
+		if inDir(geoipDir, str) {
+			geoipStructs = append(geoipStructs, str)
+		}
 	}
+	structs = append(structs, otherStructs...)
+	relativePaths(gochanRoot, structs)
+	return structs, geoipStructs, nil
+}
 
-	f, err := parser.ParseFile(fset, filename, string(ba), parser.ParseComments|parser.DeclarationErrors)
-	if err != nil {
-		panic(err)
+// relativePaths makes the file paths of the given structs and their fields relative to gochanRoot
+func relativePaths(gochanRoot string, structs []cfgdoc.Struct) {
+	for s, str := range structs {
+		if rel, err := filepath.Rel(gochanRoot, str.File); err == nil {
+			structs[s].File = filepath.ToSlash(rel)
+		}
+		for f := range str.Fields {
+			if rel, err := filepath.Rel(gochanRoot, str.Fields[f].File); err == nil {
+				str.Fields[f].File = filepath.ToSlash(rel)
+			}
+		}
+	}
+}
+
+// missingStructs returns a description of each struct in compositeStructTypes and explicitlyNamedStructTypes that
+// isn't in structs, e.g. because it was renamed in gochan, which would otherwise leave it out of the documentation
+func missingStructs(structs []cfgdoc.Struct) []string {
+	var missing []string
+	for listName, list := range map[string][]string{
+		"compositeStructTypes":       compositeStructTypes,
+		"explicitlyNamedStructTypes": explicitlyNamedStructTypes,
+	} {
+		for _, structName := range list {
+			if !slices.ContainsFunc(structs, func(str cfgdoc.Struct) bool { return str.Name == structName }) {
+				missing = append(missing, fmt.Sprintf("struct %s (in %s)", structName, listName))
+			}
+		}
+	}
+	slices.Sort(missing)
+	return missing
+}
+
+// terminalOutput returns the width to wrap -format term output to and whether to color it. Output is only colored
+// if stdout is a terminal and the NO_COLOR environment variable isn't set. The width is the terminal's, or $COLUMNS
+// or defaultTermWidth if it isn't a terminal (or isn't a file, like a buffer passed to Generate)
+func terminalOutput(stdout io.Writer) (int, bool) {
+	isTerminal := false
+	width := 0
+	if file, ok := stdout.(*os.File); ok {
+		if info, err := file.Stat(); err == nil {
+			isTerminal = info.Mode()&os.ModeCharDevice != 0
+		}
+		width = terminalWidth(file)
 	}
-	return f
+	if width <= 0 {
+		width, _ = strconv.Atoi(os.Getenv("COLUMNS"))
+	}
+	if width <= 0 {
+		width = defaultTermWidth
+	}
+	return width, isTerminal && os.Getenv("NO_COLOR") == ""
 }
 
-type structType struct {
-	name   string
-	doc    string
-	fields []fieldType
+// stringList is a flag.Value for flags that can be given more than once
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
 }
 
-func (s *structType) isBoardConfig() bool {
-	return s.name == "BoardConfig" || s.name == "PostConfig" || s.name == "UploadConfig"
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
 }
 
-type fieldType struct {
-	composite  string
-	name       string
-	fType      string
-	defaultVal string
-	doc        string
+// Flags holds the values of the command line flags, with the same names (e.g. OutFile is -o), and the gochan root
+// given as the argument
+type Flags struct {
+	Root string
+
+	ToolConfig        string
+	ConfigDir         string
+	GeoIPDir          string
+	Compare           string
+	Format            string
+	BoardStructs      string
+	Check             string
+	OutFile           string
+	ResolveAliases    bool
+	WithSource        bool
+	ExpandSlices      bool
+	DefaultsFunc      string
+	InferDefaults     bool
+	ValidateDefaults  bool
+	ValidateStructure bool
+	Style             bool
+	StyleStrict       bool
+	StyleMaxLength    int
+	ValidateConfig    string
+	FlatSort          string
+	BoardOptionsTable bool
+	CompositeDocs     bool
+	GroupByFile       bool
+	Locales           bool
+	IncludeEnums      bool
+	MaxDefaultWidth   int
+	Wrap              int
+	EnvPrefix         string
+	FieldAnchors      bool
+	AutoLink          bool
+	CacheDir          string
+	BuildTags         string
+	ResolveConstants  bool
+	IncludeUnexported bool
+	Recursive         bool
+	DurationFormat    string
+	Quiet             bool
+	Verbose           bool
+	List              bool
+	Summary           bool
+	HeaderFile        string
+	NoHeader          bool
+	IncludePackageDoc bool
+	Align             bool
+	TemplateFile      string
+	Standalone        bool
+	Only              stringList
+	Exclude           stringList
+	FieldOrder        stringList
 }
 
-func docStructs(dir string) (map[string]structType, error) {
-	structMap := make(map[string]structType)
-	fset := token.NewFileSet()
-	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
+// isOutputDir returns true if the -o path is a directory for -format markdown-split to write its files to, because
+// it ends with a slash or is an existing directory. Otherwise the combined Markdown is written to it as a file
+func isOutputDir(outPath string) bool {
+	if outPath == "" {
+		return false
+	}
+	if strings.HasSuffix(outPath, "/") || strings.HasSuffix(outPath, string(filepath.Separator)) {
+		return true
+	}
+	info, err := os.Stat(outPath)
+	return err == nil && info.IsDir()
+}
+
+// writeMarkdownFiles writes the files rendered by cfgdoc.RenderMarkdownFiles to dir, creating it if it doesn't
+// exist
+func writeMarkdownFiles(dir string, files []cfgdoc.MarkdownFile) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := os.WriteFile(filepath.Join(dir, file.Name), []byte(file.Content), 0644); err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		if filepath.Ext(path) != ".go" || strings.HasSuffix(path, "_test.go") {
-			return nil
+// register defines the command line flags in fs, setting the fields of flags to their defaults
+func (flags *Flags) register(fs *flag.FlagSet) {
+	fs.Var(&flags.Only, "only", "only document the given struct as a standalone table, can be repeated")
+	fs.Var(&flags.Exclude, "exclude", "leave the given struct (StructName) or field (StructName.FieldName) out of the documentation, can be repeated")
+	fs.StringVar(&flags.ToolConfig, "tool-config", "",
+		"read options from the given YAML or JSON file, by default "+strings.Join(toolConfigFiles, ", or ")+" in the current directory if it exists. Command line flags override it")
+	fs.StringVar(&flags.ConfigDir, "config-dir", "pkg/config", "directory of the config package, relative to the gochan root")
+	fs.StringVar(&flags.GeoIPDir, "geoip-dir", "pkg/posting/geoip", "directory of the geoip package, relative to the gochan root")
+	fs.Var(&flags.FieldOrder, "field-order",
+		"render the given fields of a struct first, in the given order (StructName:FieldA,FieldB), followed by the rest in source order, can be repeated")
+	fs.StringVar(&flags.Compare, "compare", "",
+		"compare the config structs of the given older gochan tree with the ones in the gochan root, writing the added, removed, and changed fields as Markdown instead of generating documentation")
+	fs.StringVar(&flags.Format, "format", formatMarkdown, "output format: "+strings.Join(outputFormats, ", "))
+	fs.StringVar(&flags.BoardStructs, "board-structs", strings.Join(boardStructTypes, ","),
+		"comma-separated list of structs whose fields can be overridden in board.json, unless a struct's doc comment has a BoardOption annotation")
+	fs.StringVar(&flags.Check, "check", "",
+		"compare the generated documentation against the given file instead of printing it, printing a diff and exiting with a non-zero status if they differ")
+	fs.StringVar(&flags.OutFile, "o", "",
+		"write the generated documentation to the given file instead of stdout, or with -format "+formatMarkdownSplit+", to a file per struct and "+cfgdoc.IndexFile+" in the given directory if it ends with a slash or is an existing directory")
+	fs.BoolVar(&flags.ResolveAliases, "resolve-aliases", false,
+		"show the type that declared non-struct types and aliases (e.g. type BoardID int) resolve to next to their name")
+	fs.BoolVar(&flags.WithSource, "with-source", false, "append the file and line where each field is declared to its Info column")
+	fs.BoolVar(&flags.ExpandSlices, "expand-slices", false,
+		"write a sub-table of the element struct's fields after each field that is a slice of structs (e.g. []PageBanner)")
+	fs.StringVar(&flags.DefaultsFunc, "defaults-func", "",
+		"name of a function or variable in the config package whose struct literals set default values, used for fields without a Default annotation")
+	fs.BoolVar(&flags.InferDefaults, "infer-defaults", false,
+		"show the zero value of their type (false, 0, or \"\") as the default of bool, numeric, and string fields without a Default annotation or a value from -defaults-func")
+	fs.BoolVar(&flags.ValidateDefaults, "validate-defaults", false,
+		"report fields whose Default annotation isn't a valid value of their type (e.g. a non-numeric default on an int field) or doesn't match the value from -defaults-func and exit with a non-zero status if there are any")
+	fs.BoolVar(&flags.ValidateStructure, "validate-structure", false,
+		"check that the composite structs documented in the combined table are the ones embedded by "+configStructType+", reporting any differences to stderr and exiting with a non-zero status if there are any")
+	fs.BoolVar(&flags.Style, "style", false,
+		"warn about field docs that aren't complete sentences (starting with a lower case letter or not ending with punctuation) or are longer than -style-max-length, before generating documentation")
+	fs.BoolVar(&flags.StyleStrict, "style-strict", false, "like -style, but exit with a non-zero status if there are any warnings")
+	fs.IntVar(&flags.StyleMaxLength, "style-max-length", 250, "with -style, the maximum length of a field's doc in characters (0 disables the check)")
+	fs.StringVar(&flags.ValidateConfig, "validate-config", "",
+		"check the given gochan.json against the config structs instead of generating documentation, reporting unknown keys, missing required keys, set deprecated keys, and values of the wrong type, and exit with a non-zero status if there are any")
+	fs.StringVar(&flags.FlatSort, "flat-sort", "",
+		"with the markdown and html formats, sort the fields of the combined table into one list by the given key (only \"name\" is supported), with a Struct column giving the struct each one is declared in")
+	fs.BoolVar(&flags.BoardOptionsTable, "board-options-table", false,
+		"with the markdown formats, write a \""+cfgdoc.BoardOptionsHeading+"\" section with a table of only the fields that can be overridden in board.json")
+	fs.BoolVar(&flags.CompositeDocs, "composite-docs", false,
+		"split the combined table by the struct each field is declared in, with the struct's name and doc before its fields")
+	fs.BoolVar(&flags.GroupByFile, "group-by-file", false,
+		"with the markdown formats, write a heading for each source file followed by the tables of the structs declared in it")
+	fs.BoolVar(&flags.Locales, "locales", false,
+		"list the locales supported by the geoip package as the allowed values of "+geoipLocaleKey+" in the GeoIPOptions example")
+	fs.BoolVar(&flags.IncludeEnums, "include-enums", false,
+		"with the markdown and html formats, write a Constants table with the exported constants of each declared type in the config and geoip packages after the struct tables")
+	fs.IntVar(&flags.MaxDefaultWidth, "max-default-width", 0,
+		"truncate defaults longer than the given number of characters in the Default column of the tables, ending them with an ellipsis (0 shows them in full)")
+	fs.IntVar(&flags.Wrap, "wrap", 0,
+		"with the markdown formats, soft-wrap the Info column at the given number of characters using <br>, without splitting inline code (0 disables wrapping)")
+	fs.StringVar(&flags.EnvPrefix, "env-prefix", "GOCHAN_", "with -format dotenv, the prefix of the environment variable names")
+	fs.BoolVar(&flags.FieldAnchors, "field-anchors", false,
+		"give each field an id to link to, like siteconfig-sitename, as the id of its row in html output or an anchor before its name in markdown output")
+	fs.BoolVar(&flags.AutoLink, "autolink", false,
+		"with the markdown formats, link the backtick-quoted names of other fields in field docs, like `SiteName`, to their rows")
+	fs.StringVar(&flags.CacheDir, "cache-dir", "",
+		"cache the structs parsed from each file in the given directory, so that repeated runs only parse the files that changed since the last one")
+	fs.StringVar(&flags.BuildTags, "build-tags", "",
+		"comma-separated build tags, only parse the files whose build constraints are satisfied by them and the target platform ($GOOS and $GOARCH, or the current one) instead of every file")
+	fs.BoolVar(&flags.ResolveConstants, "resolve-constants", false,
+		"replace Default annotations that name a constant declared in the config or geoip package, like Default: DefaultPort, with the constant's value")
+	fs.BoolVar(&flags.IncludeUnexported, "include-unexported", false,
+		"include documented unexported fields, marked as unexported, for internal documentation (they can't be set in gochan.json)")
+	fs.BoolVar(&flags.Recursive, "recursive", false,
+		"parse every package under the gochan root instead of only -config-dir and -geoip-dir, documenting the structs marked with a //cfgdoc:config comment and the structs they use")
+	fs.StringVar(&flags.DurationFormat, "duration-format", string(cfgdoc.DurationNanoseconds),
+		"how gochan marshals time.Duration fields in JSON, for the example configs and schemas: ns (an integer number of nanoseconds, like encoding/json) or string (e.g. \"1h30m\")")
+	fs.BoolVar(&flags.Quiet, "quiet", false, "don't write warnings to stderr, only errors")
+	fs.BoolVar(&flags.Verbose, "verbose", false, "write the path of each parsed file to stderr")
+	fs.BoolVar(&flags.List, "list", false,
+		"list each parsed struct with whether it is rendered as a composite, named, or auto-included struct or ignored, and its number of documented fields, instead of generating documentation")
+	fs.BoolVar(&flags.Summary, "summary", false,
+		"append a summary of how many fields are documented, as an HTML comment with the markdown and html formats or to stderr otherwise")
+	fs.StringVar(&flags.HeaderFile, "header", "", "replace the built-in Markdown header with the contents of the given file")
+	fs.BoolVar(&flags.IncludePackageDoc, "include-package-doc", false,
+		"with the markdown formats, write the doc comment of the config package after the header, as an introduction")
+	fs.BoolVar(&flags.NoHeader, "no-header", false, "leave out the Markdown header")
+	fs.BoolVar(&flags.Align, "align", false,
+		"with the markdown formats, give table columns explicit alignments in their dividers, like :---, centering the Board option and Required columns")
+	fs.StringVar(&flags.TemplateFile, "template", "",
+		"render the documentation by executing the given text/template file instead of using -format, e.g. templates/markdown.tmpl")
+	fs.BoolVar(&flags.Standalone, "standalone", false, "with -format "+formatHTML+", output a complete HTML document instead of only the tables")
+}
+
+func main() {
+	var flags Flags
+	flags.register(flag.CommandLine)
+	printVersion := flag.Bool("version", false, "print the version of the tool and the Go version it was built with, and exit")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [options] /path/to/gochan/\n", os.Args[0])
+		flag.PrintDefaults()
+		fmt.Fprintf(flag.CommandLine.Output(), `
+exit codes:
+  %d  success
+  %d  invalid usage, or the output couldn't be written
+  %d  the gochan tree or the config given to -validate-config couldn't be read or parsed
+  %d  validation failed (-check, -validate-defaults, -validate-config, -validate-structure, or -style-strict)
+`, exitSuccess, exitUsage, exitParseError, exitValidationFailed)
+	}
+	// the flag package exits with status 2 for invalid flags by default, which is used for parse errors here
+	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
+	if err := flag.CommandLine.Parse(os.Args[1:]); err == flag.ErrHelp {
+		os.Exit(exitSuccess)
+	} else if err != nil {
+		os.Exit(exitUsage)
+	}
+	if *printVersion {
+		fmt.Printf("gochan-cfgdoc %s (%s)\n", toolVersion(), runtime.Version())
+		os.Exit(exitSuccess)
+	}
+	if flags.ToolConfig == "" {
+		flags.ToolConfig = findToolConfig()
+	}
+	if flags.ToolConfig != "" {
+		values, err := readToolConfig(flags.ToolConfig)
+		if err == nil {
+			err = applyToolConfig(values)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading tool config %s: %s\n", flags.ToolConfig, err)
+			os.Exit(exitUsage)
 		}
-		var structName string
-		var structDoc string
-		file := mustParse(fset, d.Name(), path)
+	}
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(exitUsage)
+	}
+	flags.Root = flag.Arg(0)
+	os.Exit(Generate(&flags, os.Stdout, os.Stderr))
+}
 
-		structDocs := make(map[string]string)
+// Generate documents the gochan tree in flags.Root as main does, writing the output to stdout (unless it is
+// written to a file) and errors, warnings, and validation problems to stderr, and returns the exit code
+func Generate(flags *Flags, stdout, stderr io.Writer) int {
+	if !slices.Contains(outputFormats, flags.Format) {
+		fmt.Fprintf(stderr, "Unrecognized output format %q\n", flags.Format)
+		return exitUsage
+	}
+	durations := cfgdoc.DurationFormat(flags.DurationFormat)
+	if durations != cfgdoc.DurationNanoseconds && durations != cfgdoc.DurationString {
+		fmt.Fprintf(stderr, "Unrecognized duration format %q, expected %s or %s\n", flags.DurationFormat,
+			cfgdoc.DurationNanoseconds, cfgdoc.DurationString)
+		return exitUsage
+	}
 
-		ast.Inspect(file, func(n ast.Node) bool {
-			switch t := n.(type) {
-			case *ast.TypeSpec:
-				structName = t.Name.String()
-				// fmt.Println(structName, "doc:", t)
-				if t.Doc == nil {
-					structDoc = structDocs[structName]
-				} else {
-					structDoc = t.Doc.Text()
-				}
-			case *ast.StructType:
-				st := structType{
-					name: structName,
-					doc:  structDoc,
-				}
-				for _, field := range t.Fields.List {
-					var fieldT fieldType
-					if field.Names == nil {
-						fieldT.composite = field.Type.(*ast.Ident).Obj.Name
-					} else {
-						fieldT.name = field.Names[0].String()
-					}
-					if field.Doc.Text() == "" {
-						// field has no documentation, skip it
-						continue
-					}
-
-					if field.Doc != nil {
-						fieldT.doc = field.Doc.Text()
-					}
-					docLines := strings.Split(fieldT.doc, "\n")
-					fieldT.doc = ""
-					for _, line := range docLines {
-						if strings.HasPrefix(strings.ToLower(line), "default: ") && fieldT.defaultVal == "" {
-							fieldT.defaultVal = line[9:]
-							break
-						}
-						fieldT.doc += line + "\n"
-					}
-
-					switch tt := field.Type.(type) {
-					case *ast.Ident:
-						fieldT.fType = tt.Name
-					case *ast.ArrayType:
-						if selectorExpr, ok := tt.Elt.(*ast.SelectorExpr); ok {
-							fieldT.fType = "[]" + fmt.Sprintf("%v.%v", selectorExpr.X, selectorExpr.Sel)
-						} else {
-							fieldT.fType = "[]" + fmt.Sprint(tt.Elt)
-						}
-					case *ast.MapType:
-						fieldT.fType = fmt.Sprintf("map[%v]%v", tt.Key, tt.Value)
-					case *ast.StarExpr:
-						fieldT.fType = fmt.Sprint(tt.X)
-					default:
-						panic(fmt.Sprintf("%#v", field.Type))
-					}
-					st.fields = append(st.fields, fieldT)
-				}
-				structMap[structName] = st
-			case *ast.File:
-				// fmt.Println("file name", t.Name)
-			case *ast.ImportSpec:
-			case *ast.BasicLit:
-				// fmt.Println("basiclit:", t.Kind)
-			case *ast.ValueSpec:
-				// fmt.Println("valuespec:", t)
-				if t.Doc != nil {
-					fmt.Println("ValueSpec doc:", t.Doc.Text())
-				}
-			case *ast.StarExpr:
-				// fmt.Println("starexpr:", t)
-			case *ast.CompositeLit:
-				// fmt.Println("compositelit:", t)
-			case *ast.MapType:
-				// fmt.Println("maptype:", t)
-			case *ast.ArrayType:
-				// fmt.Println("arraytype:", t)
-			case *ast.FieldList:
-				// fmt.Println("fieldlist:", t)
-			case *ast.Field:
-				// fmt.Println("field:", t)
-			case *ast.BlockStmt:
-				// fmt.Println("blockstmt:", t)
-			case *ast.GenDecl:
-				doc := t.Doc.Text()
-				if doc != "" {
-					firstSpace := strings.Index(doc, " ")
-					if firstSpace > 0 {
-						probableName := doc[:firstSpace]
-						structDocs[probableName] = doc
-					}
+	fieldSort := cfgdoc.FieldSort(flags.FlatSort)
+	if fieldSort != cfgdoc.FieldSortNone && fieldSort != cfgdoc.FieldSortName {
+		fmt.Fprintf(stderr, "Unrecognized -flat-sort key %q, expected %s\n", flags.FlatSort, cfgdoc.FieldSortName)
+		return exitUsage
+	}
+
+	gochanRoot := flags.Root
+	cfgDir := path.Join(gochanRoot, flags.ConfigDir)
+	geoipDir := path.Join(gochanRoot, flags.GeoIPDir)
+	if flags.OutFile != "" && flags.Check != "" {
+		fmt.Fprintln(stderr, "-o and -check can't be used together")
+		return exitUsage
+	}
+	if flags.Quiet && flags.Verbose {
+		fmt.Fprintln(stderr, "-quiet and -verbose can't be used together")
+		return exitUsage
+	}
+	var warnings io.Writer = stderr
+	if flags.Quiet {
+		warnings = io.Discard
+	}
+	parseOpts := cfgdoc.ParseOptions{IncludeUnexported: flags.IncludeUnexported, MarkedOnly: flags.Recursive, Warnings: warnings,
+		CacheDir: flags.CacheDir}
+	if flags.Verbose {
+		parseOpts.Verbose = stderr
+	}
+	if flags.BuildTags != "" {
+		parseOpts.BuildTags = strings.Split(flags.BuildTags, ",")
+	}
+	structs, geoipStructs, err := parseTree(gochanRoot, flags.ConfigDir, flags.GeoIPDir, parseOpts)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error", err)
+		return exitParseError
+	}
+	if flags.Compare != "" {
+		oldStructs, _, err := parseTree(flags.Compare, flags.ConfigDir, flags.GeoIPDir, parseOpts)
+		if err != nil {
+			fmt.Fprintln(stderr, "Error", err)
+			return exitParseError
+		}
+		fmt.Fprint(stdout, cfgdoc.Compare(oldStructs, structs))
+		return exitSuccess
+	}
+
+	if missing := missingStructs(structs); len(missing) > 0 {
+		fmt.Fprintf(stderr, "Error: %s not found in %s\n", strings.Join(missing, ", "), gochanRoot)
+		return exitParseError
+	}
+	compositeStructs := cfgdoc.ExpandComposites(structs, compositeStructTypes)
+
+	if flags.Locales {
+		localeValues, err := cfgdoc.ParseStringList(geoipDir, geoipLocalesVar)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error parsing locales in %s: %s\n", geoipDir, err)
+			return exitParseError
+		}
+		for _, str := range geoipStructs {
+			if str.Name != geoipOptionsStruct {
+				continue
+			}
+			for f := range str.Fields {
+				if str.Fields[f].Key() == geoipLocaleKey {
+					str.Fields[f].Values = localeValues
 				}
 			}
-			return true
-		})
+		}
+	}
 
-		return nil
-	})
-	return structMap, err
-}
+	if flags.ResolveConstants {
+		for dir, dirStructs := range map[string][]cfgdoc.Struct{cfgDir: structs, geoipDir: geoipStructs} {
+			constants, err := cfgdoc.ParseConstants(dir)
+			if err != nil {
+				fmt.Fprintf(stderr, "Error parsing constants in %s: %s\n", dir, err)
+				return exitParseError
+			}
+			cfgdoc.ResolveConstants(dirStructs, constants)
+		}
+	}
 
-func fieldsAsMarkdownTable(str *structType, builder *strings.Builder, named bool, showColumnHeaders bool, lengths *columnLengths) {
-	if named {
-		builder.WriteString("## " + str.name + "\n")
-		if str.doc != "" {
-			builder.WriteString(str.doc)
+	if flags.DefaultsFunc != "" {
+		defaults, err := cfgdoc.ParseDefaults(cfgDir, flags.DefaultsFunc)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error parsing defaults in %s: %s\n", cfgDir, err)
+			return exitParseError
 		}
+		cfgdoc.SetDefaults(structs, defaults)
 	}
-	if lengths == nil {
-		lengths = &columnLengths{}
-		lengths.setLengths(*str)
+
+	if flags.InferDefaults {
+		cfgdoc.InferDefaults(structs)
 	}
 
-	if showColumnHeaders {
-		builder.WriteString("Field")
-		for range lengths.fieldLength - 4 {
-			builder.WriteRune(' ')
+	if flags.ValidateStructure {
+		problems := cfgdoc.ValidateStructure(structs, configStructType, compositeStructs)
+		for _, problem := range problems {
+			fmt.Fprintln(stderr, problem)
+		}
+		if len(problems) > 0 {
+			return exitValidationFailed
+		}
+	}
+
+	if flags.ValidateConfig != "" {
+		data, err := os.ReadFile(flags.ValidateConfig)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error reading %s: %s\n", flags.ValidateConfig, err)
+			return exitParseError
+		}
+		// validated before -exclude and -only leave out any structs, which are still in the config
+		validateOpts := cfgdoc.Options{CompositeStructs: compositeStructs, DurationFormat: durations}
+		problems, err := cfgdoc.ValidateConfig(structs, validateOpts, data)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error parsing %s: %s\n", flags.ValidateConfig, err)
+			return exitParseError
+		}
+		for _, problem := range problems {
+			fmt.Fprintln(stderr, flags.ValidateConfig+": "+problem)
 		}
-		builder.WriteString("|Type")
-		for range lengths.typeLength - 3 {
-			builder.WriteRune(' ')
+		if len(problems) > 0 {
+			return exitValidationFailed
 		}
+		return exitSuccess
+	}
 
-		if !named {
-			builder.WriteString("|Board option ")
+	if len(flags.Exclude) > 0 {
+		var err error
+		if structs, err = cfgdoc.Exclude(structs, flags.Exclude); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
 		}
+	}
 
-		if lengths.defaultLength > 0 {
-			builder.WriteString("|Default")
-			for range lengths.defaultLength - 4 {
-				builder.WriteRune(' ')
+	if len(flags.FieldOrder) > 0 {
+		order := make(map[string][]string, len(flags.FieldOrder))
+		for _, directive := range flags.FieldOrder {
+			structName, fieldNames, ok := strings.Cut(directive, ":")
+			if !ok || fieldNames == "" {
+				fmt.Fprintf(stderr, "Invalid -field-order %q, expected StructName:FieldA,FieldB\n", directive)
+				return exitUsage
 			}
+			order[structName] = append(order[structName], strings.Split(fieldNames, ",")...)
+		}
+		if err := cfgdoc.OrderFields(structs, order); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
 		}
+	}
 
-		builder.WriteString("|Info\n")
-		for range lengths.fieldLength + 1 {
-			builder.WriteRune('-')
+	if flags.ValidateDefaults {
+		mismatches := cfgdoc.ValidateDefaults(structs)
+		for _, mismatch := range mismatches {
+			fmt.Fprintln(stderr, mismatch)
 		}
-		builder.WriteRune('|')
-		for range lengths.typeLength + 1 {
-			builder.WriteRune('-')
+		if len(mismatches) > 0 {
+			return exitValidationFailed
 		}
-		if !named {
-			builder.WriteRune('|')
-			for range 13 {
-				builder.WriteRune('-')
-			}
+	}
+
+	if flags.Style || flags.StyleStrict {
+		problems := cfgdoc.CheckStyle(structs, flags.StyleMaxLength)
+		for _, problem := range problems {
+			fmt.Fprintln(stderr, problem)
 		}
-		if lengths.defaultLength > 0 {
-			builder.WriteRune('|')
-			for range lengths.defaultLength + 3 {
-				builder.WriteRune('-')
-			}
+		if flags.StyleStrict && len(problems) > 0 {
+			return exitValidationFailed
 		}
-		builder.WriteString("|--------------\n")
 	}
 
-	for _, field := range str.fields {
-		if strings.Contains(field.doc, "Deprecated:") {
-			continue
+	opts := cfgdoc.Options{
+		Header:              configHeader,
+		CompositeStructs:    compositeStructs,
+		NamedStructs:        explicitlyNamedStructTypes,
+		BoardStructs:        strings.Split(flags.BoardStructs, ","),
+		ResolveAliases:      flags.ResolveAliases,
+		WithSource:          flags.WithSource,
+		ExpandSliceStructs:  flags.ExpandSlices,
+		TableOfContents:     flags.Format == formatMarkdownAnchors,
+		Collapsible:         flags.Format == formatMarkdownCollapsible,
+		MinimalTables:       flags.Format == formatMarkdownMinimal,
+		AlignColumns:        flags.Align,
+		Admonitions:         flags.Format == formatMarkdownAdmonitions,
+		Footnotes:           flags.Format == formatMarkdownFootnotes,
+		Standalone:          flags.Standalone,
+		GroupByFile:         flags.GroupByFile,
+		CompositeStructDocs: flags.CompositeDocs,
+		FlatSort:            fieldSort,
+		MaxDefaultWidth:     flags.MaxDefaultWidth,
+		BoardOptionsTable:   flags.BoardOptionsTable,
+		WrapWidth:           flags.Wrap,
+		EnvPrefix:           flags.EnvPrefix,
+		DurationFormat:      durations,
+		Warnings:            warnings,
+		FieldAnchors:        flags.FieldAnchors,
+		AutoLink:            flags.AutoLink,
+	}
+	switch {
+	case flags.NoHeader && flags.HeaderFile != "":
+		fmt.Fprintln(stderr, "-header and -no-header can't be used together")
+		return exitUsage
+	case flags.NoHeader:
+		opts.Header = ""
+	case flags.HeaderFile != "":
+		header, err := os.ReadFile(flags.HeaderFile)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error reading %s: %s\n", flags.HeaderFile, err)
+			return exitUsage
+		}
+		opts.Header = string(header)
+	}
+	if flags.IncludePackageDoc {
+		// the package doc's headings are under the header's # heading
+		packageDoc, err := cfgdoc.ParsePackageDoc(cfgDir, 2)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error parsing the package doc in %s: %s\n", cfgDir, err)
+			return exitParseError
 		}
-		builder.WriteString(field.name)
-		for range lengths.fieldLength - len(field.name) + 1 {
-			builder.WriteRune(' ')
+		if packageDoc != "" {
+			opts.Header += packageDoc + "\n"
 		}
-		builder.WriteRune('|')
-		builder.WriteString(field.fType)
-		for range lengths.typeLength - len(field.fType) + 1 {
-			builder.WriteRune(' ')
+	}
+	geoipText := geoipIntro + geoipOptionsExample(geoipStructs, durations, warnings)
+	if !flags.ExpandSlices {
+		if !strings.HasSuffix(geoipText, "\n\n") {
+			geoipText += "\n"
 		}
-
-		if !named {
-			if str.isBoardConfig() {
-				builder.WriteString("|Yes          ")
-			} else {
-				builder.WriteString("|No           ")
-			}
+		geoipText += customFlagsExample
+	}
+	opts.Sections = []cfgdoc.Section{{Heading: geoipSection, Text: geoipText, Structs: geoipSectionStructs}}
+	if len(flags.Exclude) > 0 {
+		// excluded structs would otherwise be reported as missing
+		isExcluded := func(structName string) bool { return slices.Contains(flags.Exclude, structName) }
+		opts.CompositeStructs = slices.DeleteFunc(slices.Clone(opts.CompositeStructs), isExcluded)
+		opts.NamedStructs = slices.DeleteFunc(slices.Clone(opts.NamedStructs), isExcluded)
+		for s := range opts.Sections {
+			opts.Sections[s].Structs = slices.DeleteFunc(slices.Clone(opts.Sections[s].Structs), isExcluded)
 		}
-
-		builder.WriteRune('|')
-		if lengths.defaultLength > 0 {
-			builder.WriteString(field.defaultVal)
-			for range lengths.defaultLength - len(field.defaultVal) + 3 {
-				builder.WriteRune(' ')
+	}
+	if flags.Format == formatMarkdownAnchors {
+		opts.CompositeHeading = compositeHeading
+	}
+	if flags.IncludeEnums {
+		enums, err := cfgdoc.ParseEnums(cfgDir)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error parsing constants in %s: %s\n", cfgDir, err)
+			return exitParseError
+		}
+		geoipEnums, err := cfgdoc.ParseEnums(geoipDir)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error parsing constants in %s: %s\n", geoipDir, err)
+			return exitParseError
+		}
+		for _, enum := range geoipEnums {
+			enum.Type = "geoip." + enum.Type
+			enums = append(enums, enum)
+		}
+		opts.Enums = enums
+	}
+	if len(flags.Only) > 0 {
+		available := make([]string, 0, len(structs))
+		for _, str := range structs {
+			available = append(available, str.Name)
+		}
+		for _, structName := range flags.Only {
+			if !slices.Contains(available, structName) {
+				fmt.Fprintf(stderr, "Struct %q not found, available structs: %s\n", structName, strings.Join(available, ", "))
+				return exitUsage
 			}
-			builder.WriteRune('|')
 		}
-		builder.WriteString(strings.ReplaceAll(field.doc, "\n", " "))
-		builder.WriteRune('\n')
+		opts.Header = ""
+		opts.CompositeHeading = ""
+		opts.CompositeStructs = nil
+		opts.CompositeFooter = ""
+		opts.Sections = nil
+		opts.NamedStructs = flags.Only
+		opts.NoReferencedStructs = true
+		opts.TableOfContents = false
 	}
-}
 
-func main() {
-	if len(os.Args) != 2 {
-		fmt.Printf("usage: %s /path/to/gochan/", os.Args[0])
-		os.Exit(1)
+	if flags.List {
+		roles := cfgdoc.StructRoles(structs, opts)
+		w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		for _, str := range structs {
+			fmt.Fprintf(w, "%s\t%s\t%d fields\n", str.Name, roles[str.Name], len(str.Fields))
+		}
+		w.Flush()
+		return exitSuccess
 	}
 
-	gochanRoot := os.Args[1]
-	cfgDir := path.Join(gochanRoot, "pkg/config")
-	configStructs, err := docStructs(cfgDir)
-	if err != nil {
-		fmt.Printf("Error parsing package in %s: %s", cfgDir, err)
-		os.Exit(1)
+	if flags.Format == formatMarkdownSplit && flags.TemplateFile == "" && isOutputDir(flags.OutFile) {
+		files := cfgdoc.RenderMarkdownFiles(structs, opts)
+		if flags.Summary {
+			files[0].Content += "<!-- " + cfgdoc.Summary(structs, opts) + " -->\n"
+		}
+		if err := writeMarkdownFiles(flags.OutFile, files); err != nil {
+			fmt.Fprintln(stderr, "Error", err)
+			return exitUsage
+		}
+		return exitSuccess
 	}
 
-	geoipDir := path.Join(gochanRoot, "pkg/posting/geoip")
-	geoipStructs, err := docStructs(geoipDir)
-	if err != nil {
-		fmt.Printf("Error parsing package in %s: %s", geoipDir, err)
-		os.Exit(1)
+	var output string
+	switch {
+	case flags.TemplateFile != "":
+		text, err := os.ReadFile(flags.TemplateFile)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error reading %s: %s\n", flags.TemplateFile, err)
+			return exitUsage
+		}
+		if output, err = cfgdoc.RenderTemplate(structs, opts, string(text)); err != nil {
+			fmt.Fprintf(stderr, "Error executing %s: %s\n", flags.TemplateFile, err)
+			return exitUsage
+		}
+	case flags.Format == formatHTML:
+		output = cfgdoc.RenderHTML(structs, opts) + "\n"
+	case flags.Format == formatCSV:
+		output = cfgdoc.RenderCSV(structs, opts, ',')
+	case flags.Format == formatTSV:
+		output = cfgdoc.RenderCSV(structs, opts, '\t')
+	case flags.Format == formatExampleJSON:
+		output = cfgdoc.RenderExampleJSON(structs, opts) + "\n"
+	case flags.Format == formatYAML:
+		output = cfgdoc.RenderYAML(structs, opts)
+	case flags.Format == formatOpenAPI:
+		output = cfgdoc.RenderOpenAPI(structs, opts)
+	case flags.Format == formatProto:
+		output = cfgdoc.RenderProto(structs, opts)
+	case flags.Format == formatMan:
+		output = cfgdoc.RenderMan(structs, opts)
+	case flags.Format == formatDotenv:
+		output = cfgdoc.RenderDotenv(structs, opts)
+	case flags.Format == formatTerm:
+		width, color := terminalOutput(stdout)
+		output = cfgdoc.RenderTerminal(structs, opts, width, color)
+	default:
+		output = cfgdoc.RenderMarkdown(structs, opts) + "\n"
 	}
 
-	var builder strings.Builder
-	builder.WriteString(configHeader)
-
-	cfgColumnLengths := columnLengths{}
-	configStructsArray := make([]structType, 0, len(configStructs))
-	for _, str := range compositeStructTypes {
-		configStructsArray = append(configStructsArray, configStructs[str])
+	if flags.Summary {
+		line := cfgdoc.Summary(structs, opts)
+		if flags.TemplateFile == "" && (flags.Format == formatHTML || strings.HasPrefix(flags.Format, formatMarkdown)) {
+			output += "<!-- " + line + " -->\n"
+		} else {
+			fmt.Fprintln(stderr, line)
+		}
 	}
-	cfgColumnLengths.setLengths(configStructsArray...)
 
-	for s, structName := range compositeStructTypes {
-		str := configStructs[structName]
-		fieldsAsMarkdownTable(&str, &builder, false, s == 0, &cfgColumnLengths)
+	if flags.Check != "" {
+		existing, err := os.ReadFile(flags.Check)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error reading %s: %s\n", flags.Check, err)
+			return exitUsage
+		}
+		if diff := unifiedDiff(flags.Check, "generated", string(existing), output); diff != "" {
+			fmt.Fprint(stderr, diff)
+			return exitValidationFailed
+		}
+		return exitSuccess
 	}
-	builder.WriteString("\nExample options for `GeoIPOptions`:\n")
-	builder.WriteString("```JSONC\n" +
-		"\"GeoIPType\": \"mmdb\",\n" +
-		"\"GeoIPOptions\": {\n" +
-		"\t\"dbLocation\": \"/usr/share/geoip/GeoIP2.mmdb\",\n" +
-		"\t\"isoCode\": \"en\" // optional\n" +
-		"}\n```\n\n" +
-		"`CustomFlags` is an array with custom post flags, selectable via dropdown. The `Flag` value is assumed to be a file in /static/flags/. Example:\n" +
-		"```JSON\n" +
-		"\"CustomFlags\": [\n" +
-		"\t{\"Flag\":\"california.png\", \"Name\": \"California\"},\n" +
-		"\t{\"Flag\":\"cia.png\", \"Name\": \"CIA\"},\n" +
-		"\t{\"Flag\":\"lgbtq.png\", \"Name\": \"LGBTQ\"},\n" +
-		"\t{\"Flag\":\"ms-dos.png\", \"Name\": \"MS-DOS\"},\n" +
-		"\t{\"Flag\":\"stallman.png\", \"Name\": \"Stallman\"},\n" +
-		"\t{\"Flag\":\"templeos.png\", \"Name\": \"TempleOS\"},\n" +
-		"\t{\"Flag\":\"tux.png\", \"Name\": \"Linux\"},\n" +
-		"\t{\"Flag\":\"windows9x.png\", \"Name\": \"Windows 9x\"}\n" +
-		"]\n```\n\n")
-
-	for _, structName := range explicitlyNamedStructTypes {
-		str := configStructs[structName]
-		if str.name == "" {
-			fmt.Println(structName, str)
-			continue
+	if flags.OutFile != "" {
+		if err := os.WriteFile(flags.OutFile, []byte(output), 0644); err != nil {
+			fmt.Fprintf(stderr, "Error writing %s: %s\n", flags.OutFile, err)
+			return exitUsage
 		}
-		fieldsAsMarkdownTable(&str, &builder, true, true, nil)
-		builder.WriteString("\n")
+		return exitSuccess
 	}
-
-	country := geoipStructs["Country"]
-	country.name = "geoip.Country"
-	cfgColumnLengths.setLengths(country)
-	fieldsAsMarkdownTable(&country, &builder, true, true, &cfgColumnLengths)
-	fmt.Println(builder.String())
+	fmt.Fprint(stdout, output)
+	return exitSuccess
 }
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "write the generated documentation to the golden files instead of comparing them")
+
+// testFlags returns the flags that main would run with given args, documenting the gochan tree in root
+func testFlags(t *testing.T, root string, args ...string) *Flags {
+	t.Helper()
+	var flags Flags
+	fs := flag.NewFlagSet("gochan-cfgdoc", flag.ContinueOnError)
+	flags.register(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	flags.Root = root
+	return &flags
+}
+
+// generate runs Generate with flags and returns what it wrote to stdout, failing the test if it didn't succeed
+func generate(t *testing.T, flags *Flags) string {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	if code := Generate(flags, &stdout, &stderr); code != exitSuccess {
+		t.Fatalf("Generate exited with %d:\n%s", code, stderr.String())
+	}
+	return stdout.String()
+}
+
+func TestGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		root   string
+		golden string
+	}{
+		{name: "gochan", root: "testdata/gochan", golden: "testdata/golden.md"},
+		// none of the fields have defaults, so none of the tables have a Default column
+		{name: "nodefaults", root: "testdata/nodefaults", golden: "testdata/golden-nodefaults.md"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output := generate(t, testFlags(t, test.root))
+			if *update {
+				if err := os.WriteFile(test.golden, []byte(output), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			golden, err := os.ReadFile(test.golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := unifiedDiff(test.golden, "generated", string(golden), output); diff != "" {
+				t.Errorf("the generated documentation doesn't match %s, run go generate to update it if the changes are intended:\n%s",
+					test.golden, diff)
+			}
+		})
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	// auto-included structs and map iteration must not make the output differ between runs
+	for _, format := range outputFormats {
+		if format == formatMarkdownSplit {
+			// the files are only written with -o
+			continue
+		}
+		t.Run(format, func(t *testing.T) {
+			first := generate(t, testFlags(t, "testdata/gochan", "-format", format))
+			second := generate(t, testFlags(t, "testdata/gochan", "-format", format))
+			if first != second {
+				t.Errorf("two runs gave different output:\n%s", unifiedDiff("first", "second", first, second))
+			}
+		})
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	// the required fields, and EmbedMatchers with ThumbnailURLTemplate, which has no doc comment
+	const validConfig = `{
+	"DocumentRoot": "/srv/gochan/html",
+	"TemplateDir": "/srv/gochan/templates",
+	"DBtype": "sqlite3",
+	"DBhost": "gochan.db",
+	"EmbedMatchers": {"yt": {"URLRegex": "youtube", "ThumbnailURLTemplate": "https://img.youtube.com/vi/{{.MediaID}}/0.jpg"}}
+}`
+	tests := []struct {
+		name     string
+		config   string
+		args     []string
+		expected int
+	}{
+		{name: "valid", config: validConfig, expected: exitSuccess},
+		// the config is still checked against the structs that -only leaves out of the documentation
+		{name: "only", config: validConfig, args: []string{"-only", "Style"}, expected: exitSuccess},
+		{name: "exclude", config: validConfig, args: []string{"-exclude", "EmbedMatcher"}, expected: exitSuccess},
+		{
+			name:     "unknown key",
+			config:   `{"DocumentRoot": "/srv", "TemplateDir": "/srv", "DBtype": "sqlite3", "DBhost": "gochan.db", "DocumentRot": ""}`,
+			expected: exitValidationFailed,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "gochan.json")
+			if err := os.WriteFile(path, []byte(test.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+			flags := testFlags(t, "testdata/gochan", append(test.args, "-validate-config", path)...)
+			var stdout, stderr bytes.Buffer
+			if code := Generate(flags, &stdout, &stderr); code != test.expected {
+				t.Errorf("expected Generate to exit with %d, got %d:\n%s", test.expected, code, stderr.String())
+			}
+		})
+	}
+}
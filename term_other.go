@@ -0,0 +1,10 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package main
+
+import "os"
+
+// terminalWidth returns 0, since getting the size of the terminal isn't supported on this platform
+func terminalWidth(*os.File) int {
+	return 0
+}
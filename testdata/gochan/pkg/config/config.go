@@ -0,0 +1,436 @@
+package config
+
+import (
+	"time"
+
+	"github.com/gochan-org/gochan/pkg/posting/geoip"
+)
+
+// GochanConfig stores important info and is read from/written to gochan.json
+//
+//cfgdoc:config
+type GochanConfig struct {
+	SystemCriticalConfig
+	SiteConfig
+	BoardConfig
+	jsonLocation string
+	testing      bool
+}
+
+// SQLConfig contains the settings gochan uses to connect to its database
+type SQLConfig struct {
+	// DBtype is the type of SQL database to use. Currently supported values are "mysql", "postgres", and "sqlite3"
+	// Required: true
+	DBtype string
+
+	// DBhost is the database host or the path to the SQLite database file
+	// Required: true
+	DBhost string
+
+	// DBname is the name of the SQL database to connect to
+	DBname string
+
+	// DBusername is the database username
+	DBusername string
+
+	// DBpassword is the database user's password
+	// Sensitive
+	DBpassword string
+
+	// DBprefix is the prefix to use for table names
+	// Default: gc_
+	DBprefix string
+
+	// DBmaxOpenConns is the maximum number of open connections to the database
+	// Default: 10
+	DBmaxOpenConns int
+
+	// DBmaxIdleConns is the maximum number of idle connections to the database
+	// Default: 10
+	DBmaxIdleConns int
+
+	// DBconnMaxLifetime is the maximum amount of time a database connection may be reused, or 0 to reuse
+	// connections forever
+	// Default: 3m
+	DBconnMaxLifetime time.Duration
+}
+
+/*
+SystemCriticalConfig contains configuration options that are extremely important, and fucking with them while
+the server is running could have site breaking consequences. It should only be changed by modifying the configuration
+file and restarting the server.
+*/
+type SystemCriticalConfig struct {
+	// ListenAddress is the IP address or domain name that the server will listen on
+	// Group: listener
+	ListenAddress string
+
+	// UnixSocket is the path of a Unix socket to listen on instead of ListenAddress
+	// Group: listener
+	// Platform: linux, bsd
+	UnixSocket string
+
+	// Port is the port that the server will listen on
+	// Default: 80
+	Port int
+
+	// UseFastCGI tells the server to listen on FastCGI instead of HTTP if true
+	UseFastCGI bool
+
+	// DocumentRoot is the path to the directory that contains the served static files
+	// Required
+	// Example: "/srv/gochan/html"
+	DocumentRoot string
+
+	// TemplateDir is the path to the directory that contains the template files
+	// Required
+	TemplateDir string
+
+	// LogDir is the path to the directory that will contain the log files. It must be writable by the server and will be created if it doesn't exist
+	// Default[linux]: /var/log/gochan
+	// Default[windows]: C:\ProgramData\gochan\log
+	LogDir string
+
+	// Plugins is a list of Go plugins or Lua scripts to be loaded at startup
+	Plugins []string
+
+	// PluginSettings is a key/value map of settings for plugins
+	// Keys: plugin name
+	PluginSettings map[string]any
+
+	// WebRoot is the base URL path that the site is rooted at. It replaces the Deprecated: SiteWebfolder option
+	// Default: /
+	WebRoot string
+
+	SQLConfig
+
+	// CheckRequestReferer tells the server to validate the Referer header from requests to prevent CSRF attacks.
+	// Default: true
+	CheckRequestReferer bool
+
+	// Verbose enables extra logging if true
+	Verbose bool `json:"DebugMode"`
+
+	// RandomSeed is a random string used for generating secure tokens
+	RandomSeed string
+
+	// Deprecated: Use WebRoot instead
+	SiteWebfolder string
+}
+
+// SiteConfig contains information about the site/community, e.g. the name of the site, the slogan (if set),
+// the first page to look for if a directory is requested, etc
+type SiteConfig struct {
+	// FirstPage is a list of page filenames that the server will look for when a directory is requested
+	// Default: ["index.html","firstrun.html","1.html"]
+	FirstPage []string
+
+	// Username is the name of the user that the server should run as, if set
+	// Platform: linux, bsd
+	Username string
+
+	// CookieMaxAge is the amount of time before a cookie expires, using Go's duration format
+	// Default: 1y
+	CookieMaxAge string
+
+	// Lockdown prevents users from posting if true
+	// Default: false
+	// BoardOption: true
+	Lockdown bool
+
+	// LockdownMessage is the message displayed to users if they try to cretae a post when the site is in lockdown
+	// (`Lockdown` is true)
+	// Default: This imageboard has temporarily disabled posting. We apologize for the inconvenience
+	LockdownMessage string
+
+	// SiteName is the name of the site, displayed in the title and front page header
+	// Default: Gochan
+	SiteName string
+
+	// SiteSlogan is the community slogan displayed on the front page below the site name
+	SiteSlogan string
+
+	// MaxRecentPosts is the number of recent posts to show on the front page
+	// Default: DefaultMaxRecentPosts
+	MaxRecentPosts int
+
+	// GeoIPType is the type of GeoIP database to use. Currently only "mmdb" is supported
+	GeoIPType string
+
+	// GeoIPOptions is a map of options to pass to the GeoIP initializer
+	GeoIPOptions map[string]any
+
+	// Captcha options for spam prevention. Currently only hcaptcha is supported
+	Captcha CaptchaConfig
+
+	// FeaturedBanners are the banners displayed on the front page. Unlike the banners in Banners, they aren't
+	// displayed on board pages
+	// See: BoardConfig.Banners
+	FeaturedBanners []*PageBanner
+
+	// Maintenance is a notice displayed at the top of every page while the site is undergoing maintenance. If it
+	// isn't set, no notice is displayed
+	// Optional
+	Maintenance *MaintenanceNotice
+
+	// FingerprintHashLength is the length of the hash used for image fingerprinting
+	// Default: 16
+	// Since: v3.10
+	FingerprintHashLength int
+
+	*EmbeddedConfig
+}
+
+// EmbeddedConfig contains site settings that are embedded in SiteConfig by pointer
+type EmbeddedConfig struct {
+	// EnableRSS determines whether to generate RSS feeds for boards and threads
+	EnableRSS bool
+
+	// RSSItemCount is the number of posts to include in each RSS feed, if `EnableRSS` is set
+	// Default: 20
+	RSSItemCount int
+}
+
+// CaptchaConfig contains information about the captcha service used by the site
+type CaptchaConfig struct {
+	// Type is the type of captcha to use. Currently only "hcaptcha" is supported
+	Type string
+
+	// OnlyNeededForThreads determines whether to require a captcha only when creating a new thread, or for all posts
+	OnlyNeededForThreads bool
+
+	// SiteKey is the public key for the captcha service
+	SiteKey string
+
+	// AccountSecret is the secret key for the captcha service
+	// Default: changeme
+	// Sensitive
+	AccountSecret string
+
+	// Deprecated: Use Type instead
+	// Default: hcaptcha
+	Provider string
+}
+
+// MaintenanceNotice is a notice about scheduled or ongoing maintenance
+type MaintenanceNotice struct {
+	// Message is the text of the notice
+	Message string
+
+	// Until is when the maintenance is expected to end, shown after the message if it is set
+	// Example: "2024-01-02 15:00 UTC"
+	Until string `json:",omitempty"`
+
+	NoticeStyle
+}
+
+// NoticeStyle sets how a notice is displayed. Its fields are set in the notice's own object, since it is embedded
+type NoticeStyle struct {
+	// Color is the CSS background color of the notice
+	// Default: #ffd
+	Color string
+}
+
+// BoardCooldowns defines the time in seconds that the user must wait before they can make a new post
+type BoardCooldowns struct {
+	// NewThread is the time in seconds that the user must wait before they can make a new thread.
+	// DEFAULT: 30
+	// Units: seconds
+	NewThread int `json:"threads"`
+
+	// Reply is the time in seconds that the user must wait after making a post before they can make a threaded reply
+	// default:7
+	// Units: seconds
+	Reply int `json:"replies"`
+
+	// ImageReply is the time in seconds that the user must wait after making a post before they can make a reply with an image
+	// Default:   20
+	ImageReply int `json:"images"`
+}
+
+// PageBanner represents the filename and dimensions of a banner image to display on board and thread pages
+type PageBanner struct {
+	// Filename is the name of the image file to display as seen by the browser
+	Filename string
+	// Width and Height are the dimensions of the image in pixels
+	Width, Height int
+}
+
+// A Style represents a theme (Pipes, Dark, etc) selectable from the frontend
+type Style struct {
+	// Name is the display name of the style
+	Name string
+	// Filename is the name of the CSS file in /css/
+	Filename string
+}
+
+// BoardConfig contains information about a specific board to be stored in /path/to/board/board.json
+// or all boards if it is stored in the main gochan.json file. If a board doesn't have board.json,
+// the site's default board config (with values set in gochan.json) will be used
+type BoardConfig struct {
+	// InheritGlobalStyles determines whether to use the global styles in addition to the board's styles, as opposed to only the board's styles
+	InheritGlobalStyles bool
+
+	// Styles is a list of Gochan themes with Name and Filename fields, choosable from the frontend
+	Styles []Style
+
+	// DefaultStyle is the filename of the default style to use for the board or the site. If it is not set, the first style in the Styles array will be used
+	// Default: pipes.css
+	DefaultStyle string
+
+	// Banners is a list of page banners to display on board pages
+	Banners []PageBanner
+
+	// DefaultBanner is the banner displayed on board pages if Banners is empty
+	// Accepts: a filename string, or a PageBanner object
+	// See: Banners, PageBanner
+	DefaultBanner interface{}
+
+	PostConfig
+	UploadConfig
+
+	// DateTimeFormat is the human readable format to use for showing post timestamps. See [the official documentation](https://pkg.go.dev/time#Time.Format) for more information.
+	// Default: Mon, January 02, 2006 3:04 PM
+	DateTimeFormat string
+
+	// ShowPosterID determines whether to show the generated thread-unique poster ID in the post header (not yet implemented)
+	// BoardOption: false
+	ShowPosterID bool
+
+	// Cooldowns is used to prevent spamming by setting the number of seconds the user must wait before creating new threads or replies
+	Cooldowns BoardCooldowns
+
+	// ThreadsPerPage is the number of threads to display per page
+	// Default: 20
+	ThreadsPerPage int
+
+	// EnableGeoIP shows the IP country flag in the post header if true
+	EnableGeoIP bool
+
+	// CustomFlags is a list of non-geoip flags with Name (viewable to the user) and Flag (flag image filename) fields
+	CustomFlags []geoip.Country
+
+	// isGlobal is set for the site-wide board config, as opposed to the config read from a board's board.json
+	isGlobal bool
+}
+
+// PostConfig contains information and settings for posts
+type PostConfig struct {
+	// MaxLineLength is the maximum number of characters in a line of a post
+	// Default: 150
+	MaxLineLength int
+
+	// ReservedTrips is a map of tripcode strings that are reserved
+	ReservedTrips map[string]string
+
+	// WordFilters is a list of words to replace in post messages
+	WordFilters []WordFilter
+
+	// ThreadsPerPage is the number of threads to show per board page
+	// Default: 15
+	ThreadsPerPage int
+
+	// RepliesOnBoardPage is the number of replies to show per thread on board pages
+	// Default: 3
+	RepliesOnBoardPage int
+
+	// NewThreadsRequireUpload determines whether to require an upload to create a new thread
+	NewThreadsRequireUpload bool
+
+	// EnableEmbeds determines whether to allow embedding videos from certain sites
+	EnableEmbeds bool
+
+	// EmbedMatchers is a map of site names to the regular expressions used to match embeddable URLs
+	// Since: v4.0
+	// Keys: site name, e.g. youtube
+	EmbedMatchers map[string]EmbedMatcher
+}
+
+// WordFilter is a word or regular expression to replace in post messages. Word filters are now managed from the
+// staff menu and stored in the database
+type WordFilter struct {
+	// Deprecated: word filters are managed from the staff menu
+	Match string
+
+	replacement string
+}
+
+// EmbedMatcher contains the regular expressions used to detect embeddable URLs and generate their thumbnails
+type EmbedMatcher struct {
+	// URLRegex is the regular expression used to match an embeddable URL
+	URLRegex string
+
+	// EmbedTemplate is the template used to generate the embed HTML
+	EmbedTemplate string
+
+	ThumbnailURLTemplate string
+}
+
+// UploadConfig contains information and settings for uploads
+type UploadConfig struct {
+	// RejectDuplicateUploads determines whether to reject images that have already been uploaded
+	RejectDuplicateUploads bool
+
+	// ThumbnailWidth is the maximum width of a thumbnail in pixels
+	// Default: 200
+	ThumbnailWidth int
+
+	// ThumbnailHeight is the maximum height of a thumbnail in pixels
+	// Default: 200
+	ThumbnailHeight int
+
+	// AllowOtherExtensions is a map of file extensions to use for uploads that are not images or videos
+	// Default: {".txt": "text.png", ".pdf": "pdf.png"}
+	AllowOtherExtensions map[string]string
+
+	// MIMETypeGroups is a list of groups of MIME types that are considered the same type of file when checking
+	// for duplicate uploads, e.g. [["image/jpeg", "image/jpg"]]
+	MIMETypeGroups [][]string
+
+	// SizeLimits is a list of maps of file extensions to maximum upload sizes in bytes, checked in order
+	SizeLimits []map[string]int
+
+	// ThumbnailBackground is the RGB color used as the background of thumbnails of transparent images
+	ThumbnailBackground [3]uint8
+
+	// FallbackThumbnails are the images shown for uploads that can't be thumbnailed, for OP and reply thumbnails
+	FallbackThumbnails [numThumbnailSizes]string
+
+	// StripImageMetadata sets what (if any) metadata to remove from uploaded images using exiftool.
+	// Valid values are:
+	//   - "" keeps all metadata
+	//   - "exif" removes EXIF metadata
+	//   - "all" removes all metadata
+	// Default: exif|all
+	StripImageMetadata StripMetadataMode
+
+	// MaxFileSize is the maximum size of an uploaded file in bytes. If it isn't set, uploads of any size are accepted
+	MaxFileSize Option[int64]
+
+	// ExtensionLimits maps file extensions to the maximum number of files with that extension in a post
+	ExtensionLimits Limits[string, int]
+}
+
+// numThumbnailSizes is the number of thumbnail sizes, one for OPs and one for replies
+const numThumbnailSizes = 2
+
+// Option is a value that can be left unset, to tell an unset value apart from the zero value
+type Option[T any] struct {
+	Value T
+	Set   bool
+}
+
+// Limits maps keys to their limits
+type Limits[K comparable, V any] map[K]V
+
+// StripMetadataMode sets which metadata exiftool removes from uploaded images
+type StripMetadataMode string
+
+const (
+	// StripNone keeps all metadata
+	StripNone StripMetadataMode = ""
+	// StripExif removes EXIF metadata
+	StripExif StripMetadataMode = "exif"
+	// StripAll removes all metadata that exiftool can write
+	StripAll StripMetadataMode = "all"
+)
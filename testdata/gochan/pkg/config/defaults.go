@@ -0,0 +1,42 @@
+package config
+
+// DefaultMaxRecentPosts is the number of recent posts shown on the front page by default
+const DefaultMaxRecentPosts = 15
+
+var defaultGochanConfig = &GochanConfig{
+	SystemCriticalConfig: SystemCriticalConfig{
+		ListenAddress: "0.0.0.0",
+		Port:          80,
+		WebRoot:       "/",
+		SQLConfig: SQLConfig{
+			DBprefix:       "gc_",
+			DBmaxOpenConns: 10,
+			DBmaxIdleConns: 10,
+		},
+		CheckRequestReferer: true,
+	},
+	SiteConfig: SiteConfig{
+		FirstPage:       []string{"index.html", "firstrun.html", "1.html"},
+		CookieMaxAge:    "1y",
+		LockdownMessage: "This imageboard has temporarily disabled posting. We apologize for the inconvenience",
+		SiteName:        "Gochan",
+		MaxRecentPosts:  15,
+		Captcha: CaptchaConfig{
+			OnlyNeededForThreads: true,
+		},
+	},
+	BoardConfig: BoardConfig{
+		DefaultStyle:   "pipes.css",
+		DateTimeFormat: "Mon, January 02, 2006 3:04 PM",
+		PostConfig: PostConfig{
+			MaxLineLength:      150,
+			ThreadsPerPage:     15,
+			RepliesOnBoardPage: 3,
+		},
+		UploadConfig: UploadConfig{
+			ThumbnailWidth:  200,
+			ThumbnailHeight: 200,
+		},
+		ThreadsPerPage: 20,
+	},
+}
@@ -0,0 +1,8 @@
+// Package config reads gochan's configuration from gochan.json, which holds the options for the whole site, and
+// from each board's board.json, which can override some of them for that board.
+//
+// # Reloading
+//
+// Most options take effect when gochan is restarted. The options in SiteConfig and BoardConfig can also be reloaded
+// from the staff menu without a restart.
+package config
@@ -0,0 +1,22 @@
+package geoip
+
+// Country represents the country data (or custom flag data) used by gochan.
+type Country struct {
+	// Flag is the country abbreviation, or the filename of a custom flag in /static/flags/
+	Flag string
+	// Name is the configured flag name that shows up in the dropdown when posting
+	Name string
+}
+
+type GeoIPHandler interface {
+	GetCountry(ip string) (*Country, error)
+}
+
+// HandlerType is the kind of database a GeoIPHandler looks countries up in
+type HandlerType int
+
+const (
+	HandlerNone HandlerType = iota // HandlerNone disables country lookups
+	HandlerMMDB                    // HandlerMMDB looks countries up in a MaxMind database
+	handlerCustom
+)
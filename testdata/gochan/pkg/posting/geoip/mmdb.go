@@ -0,0 +1,56 @@
+package geoip
+
+// MMDBOptions are the options for the mmdb GeoIP handler, set in GeoIPOptions when GeoIPType is "mmdb"
+//
+//cfgdoc:config
+type MMDBOptions struct {
+	// DBLocation is the path to the GeoIP2 or GeoLite2 country database
+	// Example: "/usr/share/geoip/GeoIP2.mmdb"
+	DBLocation string `json:"dbLocation"`
+
+	// ISOCode is the language code used for country names
+	// Default: en
+	// Optional
+	ISOCode string `json:"isoCode"`
+
+	/*
+		CacheSize is the number of IP addresses whose country is kept in memory after looking it up
+		Default: 256
+	*/
+	CacheSize int `json:"cacheSize"`
+
+	Reload bool `json:"reload"` // Reload reopens the database when the file at DBLocation changes
+}
+
+// SupportedLocales are the languages that GeoIP2 databases have country names in, for the isoCode option
+var SupportedLocales = []string{"de", "en", "es", "fr", "ja", "pt-BR", "ru", "zh-CN"}
+
+type (
+	// mmdbHandler looks countries up in the database at MMDBOptions.DBLocation
+	mmdbHandler struct {
+		options MMDBOptions
+		names   mmdbNames
+	}
+
+	// mmdbNames maps language codes to country names
+	mmdbNames map[string]string
+
+	// mmdbRecord is the part of a country database record read by mmdbHandler
+	mmdbRecord struct {
+		Country struct {
+			ISOCode string    `maxminddb:"iso_code"`
+			Names   mmdbNames `maxminddb:"names"`
+		} `maxminddb:"country"`
+	}
+)
+
+func (h *mmdbHandler) GetCountry(ip string) (*Country, error) {
+	var lookup struct {
+		Record mmdbRecord
+		Found  bool
+	}
+	if !lookup.Found {
+		return nil, nil
+	}
+	return &Country{Flag: lookup.Record.Country.ISOCode, Name: lookup.Record.Country.Names[h.options.ISOCode]}, nil
+}
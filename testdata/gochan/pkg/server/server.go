@@ -0,0 +1,21 @@
+package server
+
+// Options control how requests are served, and aren't part of the configuration
+type Options struct {
+	// Compress enables gzip compression of responses
+	Compress bool
+
+	// Shutdown is closed to stop serving requests
+	Shutdown chan struct{}
+
+	// Requests receives the path of each request as it is served
+	Requests chan<- string
+
+	// OnError is called with each error returned by a handler
+	OnError func(path string, err error)
+
+	// Limits are the per-client limits
+	Limits struct {
+		Requests int
+	}
+}
@@ -0,0 +1,96 @@
+package config
+
+// GochanConfig stores important info and is read from/written to gochan.json
+type GochanConfig struct {
+	SystemCriticalConfig
+	SiteConfig
+	BoardConfig
+}
+
+// SQLConfig contains the settings gochan uses to connect to its database
+type SQLConfig struct {
+	// DBtype is the type of SQL database to use
+	// Required: true
+	DBtype string
+
+	// DBprefix is the prefix to use for table names
+	DBprefix string
+}
+
+// SystemCriticalConfig contains configuration options that should only be changed by modifying the configuration
+// file and restarting the server
+type SystemCriticalConfig struct {
+	// ListenAddress is the IP address or domain name that the server will listen on
+	// Required: true
+	ListenAddress string
+
+	// DocumentRoot is the path to the folder containing the static files
+	// Required: true
+	DocumentRoot string
+
+	// Verbose enables verbose logging
+	Verbose bool `json:"DebugMode"`
+}
+
+// SiteConfig contains information about the site/community
+type SiteConfig struct {
+	// SiteName is the name of the site, displayed in the title and front page header
+	SiteName string
+
+	// MinifyHTML sets whether gochan should minify HTML pages
+	MinifyHTML bool
+
+	// Captcha is the captcha service used by the site
+	Captcha CaptchaConfig
+}
+
+// BoardConfig contains information about a specific board
+type BoardConfig struct {
+	// Banners is a list of banners to display on the board's front page
+	Banners []PageBanner
+
+	// Cooldowns is the number of seconds the user must wait before creating new threads or replies
+	Cooldowns BoardCooldowns
+
+	PostConfig
+	UploadConfig
+}
+
+// PostConfig contains information and settings for posts
+type PostConfig struct {
+	// MaxLineLength is the maximum number of characters in a line of a post
+	MaxLineLength int
+}
+
+// UploadConfig contains information and settings for uploads
+type UploadConfig struct {
+	// AllowedExtensions are the file extensions that can be uploaded
+	AllowedExtensions []string
+}
+
+// BoardCooldowns defines the time in seconds that the user must wait before they can make a new post
+type BoardCooldowns struct {
+	// NewThread is the number of seconds the user must wait before creating a new thread
+	NewThread int `json:"threads"`
+
+	// Reply is the number of seconds the user must wait after replying
+	Reply int `json:"replies"`
+}
+
+// CaptchaConfig contains information about the captcha service used by the site
+type CaptchaConfig struct {
+	// Type is the captcha service to use
+	Type string
+
+	// SiteKey is the key given by the captcha service
+	SiteKey string
+}
+
+// PageBanner represents the filename and dimensions of a banner image
+type PageBanner struct {
+	// Filename is the name of the banner image in /static/banners/
+	Filename string
+
+	// Width is the width of the banner image in pixels
+	Width int
+}
@@ -0,0 +1,10 @@
+package geoip
+
+// Country represents the country data (or custom flag data) used by gochan.
+type Country struct {
+	// Flag is the country abbreviation, or the filename of a custom flag in /static/flags/
+	Flag string
+
+	// Name is the configured flag name that shows up in the dropdown when posting
+	Name string
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// toolConfigFiles are the tool config files looked for in the current directory if -tool-config isn't set, in
+// order of preference
+var toolConfigFiles = []string{".cfgdoc.yaml", ".cfgdoc.yml", ".cfgdoc.json"}
+
+// findToolConfig returns the first of toolConfigFiles that exists in the current directory, or an empty string if
+// there are none
+func findToolConfig() string {
+	for _, filename := range toolConfigFiles {
+		if _, err := os.Stat(filename); err == nil {
+			return filename
+		}
+	}
+	return ""
+}
+
+// readToolConfig reads a tool config file, which sets flags by their names without the leading dash, e.g.
+//
+//	format: markdown-anchors
+//	exclude:
+//	  - SiteConfig.Username
+//
+// The file is parsed as JSON if its extension is .json, or as a simple subset of YAML otherwise. Each key maps to
+// the values it gives its flag, with lists giving more than one
+func readToolConfig(filename string) (map[string][]string, error) {
+	ba, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if filepath.Ext(filename) == ".json" {
+		return parseJSONToolConfig(ba)
+	}
+	return parseYAMLToolConfig(string(ba))
+}
+
+func parseJSONToolConfig(ba []byte) (map[string][]string, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(ba, &raw); err != nil {
+		return nil, err
+	}
+	values := make(map[string][]string, len(raw))
+	for key, val := range raw {
+		list, isList := val.([]any)
+		if !isList {
+			list = []any{val}
+		}
+		for _, item := range list {
+			switch item.(type) {
+			case string, bool, float64:
+				values[key] = append(values[key], fmt.Sprint(item))
+			default:
+				return nil, fmt.Errorf("%s must be a string, boolean, number, or a list of them", key)
+			}
+		}
+	}
+	return values, nil
+}
+
+// parseYAMLToolConfig parses the subset of YAML used by tool config files: top-level "key: value" pairs, where the
+// value is a scalar, a flow list like [a, b], or left empty and followed by indented "- item" lines, with # comments
+func parseYAMLToolConfig(text string) (map[string][]string, error) {
+	values := make(map[string][]string)
+	var listKey string
+	for l, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "#") {
+			line = ""
+		} else if comment := strings.Index(line, " #"); comment >= 0 {
+			line = line[:comment]
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			if listKey == "" || trimmed == line {
+				return nil, fmt.Errorf("line %d: list item outside of a list", l+1)
+			}
+			item, err := yamlScalar(strings.TrimSpace(strings.TrimPrefix(trimmed, "-")))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", l+1, err)
+			}
+			values[listKey] = append(values[listKey], item)
+			continue
+		}
+		if trimmed != line {
+			return nil, fmt.Errorf("line %d: unexpected indentation", l+1)
+		}
+		key, val, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key: value", l+1)
+		}
+		key = strings.TrimSpace(key)
+		val = strings.TrimSpace(val)
+		listKey = ""
+		switch {
+		case val == "":
+			listKey = key
+			values[key] = nil
+		case strings.HasPrefix(val, "[") && strings.HasSuffix(val, "]"):
+			values[key] = nil
+			for _, item := range strings.Split(val[1:len(val)-1], ",") {
+				if item = strings.TrimSpace(item); item == "" {
+					continue
+				}
+				item, err := yamlScalar(item)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", l+1, err)
+				}
+				values[key] = append(values[key], item)
+			}
+		default:
+			item, err := yamlScalar(val)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", l+1, err)
+			}
+			values[key] = []string{item}
+		}
+	}
+	return values, nil
+}
+
+// yamlScalar returns the value of a plain, single-quoted, or double-quoted YAML scalar
+func yamlScalar(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		return strconv.Unquote(s)
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return "", errors.New("unterminated single-quoted string")
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+	return s, nil
+}
+
+// applyToolConfig sets the flags named in values that weren't given on the command line, so that command line
+// flags override the tool config. Repeatable flags are set once for each value, and other flags given a list are
+// set to its values joined with commas (e.g. board-structs)
+func applyToolConfig(values map[string][]string) error {
+	setOnCommandLine := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		setOnCommandLine[f.Name] = true
+	})
+	for key, vals := range values {
+		f := flag.Lookup(key)
+		if f == nil || key == "tool-config" {
+			return fmt.Errorf("unrecognized option %q", key)
+		}
+		if setOnCommandLine[key] {
+			continue
+		}
+		if _, repeatable := f.Value.(*stringList); !repeatable {
+			vals = []string{strings.Join(vals, ",")}
+		}
+		for _, val := range vals {
+			if err := f.Value.Set(val); err != nil {
+				return fmt.Errorf("invalid value %q for %s: %w", val, key, err)
+			}
+		}
+	}
+	return nil
+}